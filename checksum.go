@@ -0,0 +1,83 @@
+package s3
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+)
+
+// ChecksumAlgorithm is an algorithm S3 can use to check the
+// integrity of an object, in addition to its ETag.
+// See http://docs.aws.amazon.com/AmazonS3/latest/userguide/checking-object-integrity.html.
+type ChecksumAlgorithm string
+
+// Checksum algorithms.
+const (
+	ChecksumCRC32  ChecksumAlgorithm = "CRC32"
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+	ChecksumSHA1   ChecksumAlgorithm = "SHA1"
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+func (a ChecksumAlgorithm) new() hash.Hash {
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumCRC32C:
+		return crc32.New(crc32c)
+	case ChecksumSHA1:
+		return sha1.New()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// header returns the name of the header that holds a checksum
+// computed with a.
+func (a ChecksumAlgorithm) header() string {
+	return "X-Amz-Checksum-" + string(a)
+}
+
+// SetChecksum computes the checksum of body with algorithm a and
+// adds it to r, which uploads body, so that S3 rejects the upload
+// if the object it receives does not match. S3 stores the checksum
+// with the object. SetChecksum seeks body back to where it started.
+func SetChecksum(r *http.Request, a ChecksumAlgorithm, body io.ReadSeeker) error {
+	h := a.new()
+	if h == nil {
+		return errors.New("s3: unknown checksum algorithm " + string(a))
+	}
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(h, body); err != nil {
+		return err
+	}
+	if _, err := body.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	r.Header.Set("X-Amz-Sdk-Checksum-Algorithm", string(a))
+	r.Header.Set(a.header(), base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	return nil
+}
+
+// CheckChecksum returns an error if resp, the response to r, reports
+// a checksum different from the one SetChecksum added to r.
+// A response without a checksum is not an error.
+func CheckChecksum(r *http.Request, resp *http.Response) error {
+	a := ChecksumAlgorithm(r.Header.Get("X-Amz-Sdk-Checksum-Algorithm"))
+	want := r.Header.Get(a.header())
+	if got := resp.Header.Get(a.header()); got != "" && got != want {
+		return errors.New("s3: " + string(a) + " checksum mismatch: got " + got + ", want " + want)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var checksumTest = []struct {
+	a ChecksumAlgorithm
+	w string
+}{
+	{ChecksumCRC32, "y/Q5Jg=="},
+	{ChecksumCRC32C, "4waSgw=="},
+	{ChecksumSHA1, "98O8HYCOBHMq32eZZczDTKeuNEE="},
+	{ChecksumSHA256, "FeKw08M4keuw8e9gnsQZQgwg4yDOlMZfvIwzEkSOsiU="},
+}
+
+func TestSetChecksum(t *testing.T) {
+	for _, ts := range checksumTest {
+		body := strings.NewReader("0123456789")
+		body.Seek(1, io.SeekStart)
+		r, _ := http.NewRequest("PUT", "https://johnsmith.s3.amazonaws.com/puppy.jpg", body)
+		if err := SetChecksum(r, ts.a, body); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		name := "X-Amz-Checksum-" + string(ts.a)
+		if g := r.Header.Get(name); g != ts.w {
+			t.Errorf("%s = %q want %q", name, g, ts.w)
+		}
+		if n := body.Len(); n != 9 {
+			t.Errorf("%s: body not rewound, %d bytes left want 9", ts.a, n)
+		}
+
+		resp := &http.Response{Header: http.Header{}}
+		if err := CheckChecksum(r, resp); err != nil {
+			t.Errorf("%s: unexpected err for no checksum: %v", ts.a, err)
+		}
+		resp.Header.Set(name, ts.w)
+		if err := CheckChecksum(r, resp); err != nil {
+			t.Errorf("%s: unexpected err for matching checksum: %v", ts.a, err)
+		}
+		resp.Header.Set(name, "AAAAAA==")
+		if err := CheckChecksum(r, resp); err == nil {
+			t.Errorf("%s: expected error for mismatched checksum", ts.a)
+		}
+	}
+
+	r, _ := http.NewRequest("PUT", "https://johnsmith.s3.amazonaws.com/puppy.jpg", nil)
+	if err := SetChecksum(r, "MD4", strings.NewReader("")); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+}
@@ -1,12 +1,17 @@
 package s3
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/kr/http/transport"
 )
 
+// ErrExpired is returned for requests that would have been
+// signed with keys that have expired.
+var ErrExpired = errors.New("s3: credentials expired")
+
 // Client returns an HTTP client that signs all outgoing requests.
 // The returned Transport also provides CancelRequest.
 // Client is equivalent to DefaultService.Client.
@@ -17,12 +22,54 @@ func Client(k Keys) *http.Client {
 // Client returns an HTTP client that signs all outgoing requests.
 // The returned Transport also provides CancelRequest.
 func (s *Service) Client(k Keys) *http.Client {
-	tr := &transport.Wrapper{Modify: func(r *http.Request) error {
-		if r.Header.Get("Date") == "" {
-			r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return s.ProviderClient((*StaticProvider)(&k))
+}
+
+// ProviderClient returns an HTTP client that signs all outgoing
+// requests with keys obtained from p.
+// ProviderClient is equivalent to DefaultService.ProviderClient.
+func ProviderClient(p Provider) *http.Client {
+	return DefaultService.ProviderClient(p)
+}
+
+// ProviderClient returns an HTTP client that signs all outgoing
+// requests with keys obtained from p. It calls p.Keys for each
+// request, so p can refresh temporary keys as they expire.
+// Requests fail without being sent if the keys have expired.
+// A request with neither a Date nor an X-Amz-Date header gets
+// one of them, as chosen by s.UseAmzDate, set to the current time.
+// Signed requests are sent with s.Transport.
+// The returned Transport also provides CancelRequest.
+func (s *Service) ProviderClient(p Provider) *http.Client {
+	tr := &transport.Wrapper{Transport: s.Transport, Modify: func(r *http.Request) error {
+		k, err := p.Keys()
+		if err != nil {
+			return err
 		}
-		s.Sign(r, k)
+		if k.Expired() {
+			return ErrExpired
+		}
+		if r.Header.Get("Date") == "" && r.Header.Get("X-Amz-Date") == "" {
+			t := time.Now().UTC()
+			if s.UseAmzDate {
+				r.Header.Set("X-Amz-Date", t.Format(s.amzDateFormat()))
+			} else {
+				r.Header.Set("Date", t.Format(http.TimeFormat))
+			}
+		}
+		s.Sign(r, *k)
 		return nil
 	}}
 	return &http.Client{Transport: tr}
 }
+
+// RoundTripFunc is an http.RoundTripper that calls itself.
+// As Service.Transport, it stands in for S3 in tests: requests
+// made by Client are signed and then passed to the function,
+// without opening a connection.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(r).
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
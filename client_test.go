@@ -0,0 +1,167 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientExpiredKeys(t *testing.T) {
+	var sent int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		if r.Header.Get("Authorization") == "" {
+			t.Error("request not signed")
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	tests := []struct {
+		expires time.Time
+		err     error
+	}{
+		{time.Time{}, nil},                  // never expires
+		{now.Add(time.Minute), nil},         // about to expire
+		{now.Add(-time.Minute), ErrExpired}, // expired
+	}
+	for i, ts := range tests {
+		sent = 0
+		k := exKeys
+		k.Expires = ts.expires
+		resp, err := Client(k).Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ts.err) {
+			t.Errorf("test %d: err = %v want %v", i, err, ts.err)
+		}
+		if ts.err == nil && sent != 1 {
+			t.Errorf("test %d: request not sent", i)
+		}
+		if ts.err != nil && sent != 0 {
+			t.Errorf("test %d: request sent with expired keys", i)
+		}
+	}
+}
+
+func TestClientTransport(t *testing.T) {
+	var got []*http.Request
+	s := *DefaultService
+	s.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = append(got, r)
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+	})
+	resp, err := s.Client(exKeys).Get("https://johnsmith.s3.amazonaws.com/photos/puppy.jpg")
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	resp.Body.Close()
+	if len(got) != 1 {
+		t.Fatalf("transport saw %d requests want 1", len(got))
+	}
+	if got[0].Header.Get("Date") == "" {
+		t.Error("request has no Date")
+	}
+	if got[0].Header.Get("Authorization") == "" {
+		t.Error("request not signed")
+	}
+}
+
+func TestClientDate(t *testing.T) {
+	tests := []struct {
+		version    int
+		useAmzDate bool
+		h          http.Header
+		date       bool // whether the sent request has Date
+		amzDate    bool // whether it has X-Amz-Date
+	}{
+		{2, false, http.Header{}, true, false},
+		{2, true, http.Header{}, false, true},
+		{4, true, http.Header{}, false, true},
+		{2, false, http.Header{"X-Amz-Date": {"Fri, 24 May 2013 00:00:00 GMT"}}, false, true},
+		{4, false, http.Header{"X-Amz-Date": {"20130524T000000Z"}}, false, true},
+		{2, true, http.Header{"Date": {"Fri, 24 May 2013 00:00:00 GMT"}}, true, false},
+	}
+	for _, ts := range tests {
+		var got *http.Request
+		s := *DefaultService
+		s.Version = ts.version
+		s.UseAmzDate = ts.useAmzDate
+		s.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			got = r
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		})
+		r, _ := http.NewRequest("GET", "https://johnsmith.s3.amazonaws.com/photos/puppy.jpg", nil)
+		for k, v := range ts.h {
+			r.Header[k] = v
+		}
+		resp, err := s.Client(exKeys).Do(r)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		resp.Body.Close()
+		date, amzDate := got.Header.Get("Date"), got.Header.Get("X-Amz-Date")
+		if (date != "") != ts.date || (amzDate != "") != ts.amzDate {
+			t.Errorf("UseAmzDate %v, header %v: sent Date %q, X-Amz-Date %q", ts.useAmzDate, ts.h, date, amzDate)
+		}
+		// Version 2 wants X-Amz-Date in the same format as Date.
+		format := http.TimeFormat
+		if ts.version == 4 {
+			format = timeFormat4
+		}
+		if _, err := time.Parse(format, amzDate); amzDate != "" && err != nil {
+			t.Errorf("version %d: X-Amz-Date %q not in format %q", ts.version, amzDate, format)
+		}
+		for k, v := range ts.h {
+			if g := got.Header.Get(k); g != v[0] {
+				t.Errorf("UseAmzDate %v: %s = %q want %q", ts.useAmzDate, k, g, v[0])
+			}
+		}
+	}
+}
+
+// Run with -race to check that one client and service
+// can be shared by many goroutines.
+func TestClientConcurrent(t *testing.T) {
+	s := *V4Service
+	s.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+	})
+	newRequest := func(i int) *http.Request {
+		r, _ := http.NewRequest("GET", s.URL("johnsmith", "photos/"+strconv.Itoa(i)), nil)
+		r.Header.Set("Date", "Fri, 24 May 2013 00:00:00 GMT")
+		return r
+	}
+	want := make([]string, 10)
+	for i := range want {
+		r := newRequest(i)
+		s.Sign(r, exKeys)
+		want[i] = r.Header.Get("Authorization")
+	}
+	client := s.Client(exKeys)
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, w := range want {
+				resp, err := client.Do(newRequest(i))
+				if err != nil {
+					t.Error("unexpected err", err)
+					return
+				}
+				resp.Body.Close()
+				if g := resp.Request.Header.Get("Authorization"); g != w {
+					t.Errorf("Authorization = %q want %q", g, w)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
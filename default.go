@@ -0,0 +1,73 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultProvider returns a Provider for the keys found in the
+// first of these places that has them, as the AWS SDKs do:
+//
+//   - environment variables, as read by EnvKeys
+//   - the shared credentials file, as read by FileKeys, for the
+//     profile named by environment variable AWS_PROFILE
+//   - the IAM role of the current EC2 instance or ECS task,
+//     as fetched by IAMKeys
+//
+// If AWS_PROFILE is set, failing to read that profile is an
+// error, rather than a reason to look further. If no place has
+// keys, the error says what went wrong with each of them.
+// Outside of EC2, the last attempt may take a few seconds to
+// time out.
+func DefaultProvider() (Provider, error) {
+	k, err := EnvKeys()
+	if err == nil {
+		return (*StaticProvider)(k), nil
+	}
+	errs := []string{errText(err)}
+
+	profile := os.Getenv("AWS_PROFILE")
+	k, err = FileKeys(profile)
+	if err == nil {
+		return (*StaticProvider)(k), nil
+	}
+	if profile != "" {
+		return nil, err
+	}
+	errs = append(errs, errText(err))
+
+	k, err = IAMKeys()
+	if err == nil {
+		return &IAMProvider{k: k}, nil
+	}
+	errs = append(errs, errText(err))
+	return nil, errors.New("s3: no keys found: " + strings.Join(errs, "; "))
+}
+
+func errText(err error) string {
+	return strings.TrimPrefix(err.Error(), "s3: ")
+}
+
+// EnvRegion returns the region named by environment variable
+// AWS_REGION, or else by AWS_DEFAULT_REGION.
+func EnvRegion() string {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// Default returns a Service and a client configured from the
+// environment, as the AWS SDKs are. The service uses version 4
+// signatures in the region given by EnvRegion, and the client
+// signs requests with the keys from DefaultProvider.
+func Default() (*Service, *http.Client, error) {
+	p, err := DefaultProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &Service{Domain: "amazonaws.com", Region: EnvRegion(), Version: 4}
+	return s, s.ProviderClient(p), nil
+}
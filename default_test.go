@@ -0,0 +1,118 @@
+package s3
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultProvider(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "credentials")
+	if err := ioutil.WriteFile(name, []byte(credentialsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var imds bool // whether the metadata service has keys
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case !imds:
+			http.NotFound(w, r)
+		case r.URL.Path == "/latest/api/token":
+			w.Write([]byte("token"))
+		case strings.HasSuffix(r.URL.Path, "/security-credentials/"):
+			w.Write([]byte("role"))
+		default:
+			w.Write([]byte(iamCreds))
+		}
+	}))
+	defer srv.Close()
+	defer func(s string) { metadataURL = s }(metadataURL)
+	metadataURL = srv.URL
+
+	otherKeys := Keys{AccessKey: "AKID", SecretKey: "secret"}
+	tests := []struct {
+		env     map[string]string
+		imds    bool
+		w       Keys
+		errWant string
+	}{
+		{
+			env:  map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret"},
+			imds: true,
+			w:    otherKeys,
+		},
+		{
+			env:  map[string]string{"AWS_SHARED_CREDENTIALS_FILE": name},
+			imds: true,
+			w:    exKeys,
+		},
+		{
+			env:  map[string]string{"AWS_SHARED_CREDENTIALS_FILE": name, "AWS_PROFILE": "temp"},
+			imds: true,
+			w:    tokenExKeys,
+		},
+		{
+			env:     map[string]string{"AWS_SHARED_CREDENTIALS_FILE": name, "AWS_PROFILE": "missing"},
+			imds:    true,
+			errWant: `profile "missing" not found`,
+		},
+		{
+			env:  map[string]string{"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "none")},
+			imds: true,
+			w:    iamExKeys(),
+		},
+		{
+			env:     map[string]string{"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "none")},
+			errWant: "s3: no keys found: AWS_ACCESS_KEY_ID not set; open ",
+		},
+	}
+	for i, ts := range tests {
+		for _, v := range []string{
+			"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+			"AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE",
+			"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		} {
+			t.Setenv(v, ts.env[v])
+		}
+		imds = ts.imds
+		p, err := DefaultProvider()
+		if ts.errWant != "" {
+			if err == nil || !strings.Contains(err.Error(), ts.errWant) {
+				t.Errorf("test %d: err = %v want it to contain %q", i, err, ts.errWant)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected err %v", i, err)
+			continue
+		}
+		k, err := p.Keys()
+		if err != nil {
+			t.Errorf("test %d: unexpected err %v", i, err)
+			continue
+		}
+		if *k != ts.w {
+			t.Errorf("test %d: keys = %+v want %+v", i, *k, ts.w)
+		}
+	}
+}
+
+func TestEnvRegion(t *testing.T) {
+	tests := []struct {
+		region, defaultRegion, w string
+	}{
+		{"", "", ""},
+		{"eu-west-1", "", "eu-west-1"},
+		{"", "us-west-2", "us-west-2"},
+		{"eu-west-1", "us-west-2", "eu-west-1"},
+	}
+	for _, ts := range tests {
+		t.Setenv("AWS_REGION", ts.region)
+		t.Setenv("AWS_DEFAULT_REGION", ts.defaultRegion)
+		if g := EnvRegion(); g != ts.w {
+			t.Errorf("AWS_REGION=%q AWS_DEFAULT_REGION=%q: EnvRegion = %q want %q", ts.region, ts.defaultRegion, g, ts.w)
+		}
+	}
+}
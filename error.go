@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	maxErrorDoc  = 64 * 1024 // longest error document read
+	maxErrorBody = 512       // longest body kept in Error.Body
+)
+
+// Error is an error response from S3.
+// See http://docs.aws.amazon.com/AmazonS3/latest/API/ErrorResponses.html.
+type Error struct {
+	StatusCode int    `xml:"-"` // HTTP status code, such as 404
+	Code       string // S3 error code, such as "NoSuchKey"
+	Message    string
+	Resource   string
+	RequestID  string `xml:"RequestId"`
+	HostID     string `xml:"HostId"` // extended request ID (x-amz-id-2)
+
+	// Body holds the start of the response body if it is not
+	// an S3 error document, as may come from a proxy or some
+	// S3-compatible services. It is empty otherwise.
+	Body string `xml:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" && e.Body != "" {
+		return fmt.Sprintf("s3: %d %s: %q", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+	}
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// CheckResponse returns nil if resp has a 2xx status code.
+// Otherwise it reads and closes resp.Body and returns
+// an *Error describing the response. If the body is not
+// an S3 error document, the *Error has only the status code,
+// the request IDs, and the start of the body.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorDoc))
+	e := &Error{StatusCode: resp.StatusCode}
+	if err := xml.Unmarshal(b, e); err != nil || e.Code == "" {
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		*e = Error{StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	reqID, extID := RequestIDs(resp)
+	if e.RequestID == "" {
+		e.RequestID = reqID
+	}
+	if e.HostID == "" {
+		e.HostID = extID
+	}
+	return e
+}
+
+// RequestIDs returns the request ID and extended request ID
+// of resp, from headers X-Amz-Request-Id and X-Amz-Id-2.
+// AWS support asks for both when investigating a problem.
+func RequestIDs(resp *http.Response) (reqID, extID string) {
+	return resp.Header.Get("X-Amz-Request-Id"), resp.Header.Get("X-Amz-Id-2")
+}
@@ -0,0 +1,128 @@
+package s3
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var checkResponseTest = []struct {
+	status int
+	body   string
+	w      *Error
+}{
+	{200, "", nil},
+	{204, "", nil},
+	{
+		404,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchKey</Code>
+  <Message>The resource you requested does not exist</Message>
+  <Resource>/mybucket/myfoto.jpg</Resource>
+  <RequestId>4442587FB7D0A2F9</RequestId>
+  <HostId>eftixk72aD6Ap51TnqcoF8eFidJG9Z/2mkiDFu8yU9AS1ed4OpIszj7UDNEHGran</HostId>
+</Error>`,
+		&Error{
+			StatusCode: 404,
+			Code:       "NoSuchKey",
+			Message:    "The resource you requested does not exist",
+			Resource:   "/mybucket/myfoto.jpg",
+			RequestID:  "4442587FB7D0A2F9",
+			HostID:     "eftixk72aD6Ap51TnqcoF8eFidJG9Z/2mkiDFu8yU9AS1ed4OpIszj7UDNEHGran",
+		},
+	},
+	{
+		403,
+		`<Error><Code>AccessDenied</Code><Message>Access Denied</Message><RequestId>656c76696e6727732072657175657374</RequestId></Error>`,
+		&Error{
+			StatusCode: 403,
+			Code:       "AccessDenied",
+			Message:    "Access Denied",
+			RequestID:  "656c76696e6727732072657175657374",
+			HostID:     "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==",
+		},
+	},
+	{501, "", &Error{StatusCode: 501, HostID: "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg=="}},
+	{
+		502,
+		"<html><head><title>502 Bad Gateway</title></head><body>nginx</body></html>",
+		&Error{
+			StatusCode: 502,
+			HostID:     "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==",
+			Body:       "<html><head><title>502 Bad Gateway</title></head><body>nginx</body></html>",
+		},
+	},
+	{
+		500,
+		"<Error><Code>InternalError</Code>" + strings.Repeat(" ", maxErrorBody),
+		&Error{
+			StatusCode: 500,
+			HostID:     "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==",
+			Body:       ("<Error><Code>InternalError</Code>" + strings.Repeat(" ", maxErrorBody))[:maxErrorBody],
+		},
+	},
+}
+
+func TestCheckResponse(t *testing.T) {
+	for i, ts := range checkResponseTest {
+		resp := &http.Response{
+			StatusCode: ts.status,
+			Header: http.Header{
+				"X-Amz-Id-2": {"Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg=="},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(ts.body)),
+		}
+		err := CheckResponse(resp)
+		if ts.w == nil {
+			if err != nil {
+				t.Errorf("test %d: err = %v want nil", i, err)
+			}
+			continue
+		}
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("test %d: err = %#v want *Error", i, err)
+			continue
+		}
+		if *e != *ts.w {
+			t.Errorf("test %d: got %+v want %+v", i, *e, *ts.w)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	tests := []struct {
+		e *Error
+		w string
+	}{
+		{&Error{StatusCode: 404, Code: "NoSuchKey", Message: "Not here"}, "s3: 404 NoSuchKey: Not here"},
+		{&Error{StatusCode: 501}, "s3: 501 Not Implemented"},
+		{&Error{StatusCode: 502, Body: "Bad Gateway\n"}, `s3: 502 Bad Gateway: "Bad Gateway\n"`},
+	}
+	for _, ts := range tests {
+		if g := ts.e.Error(); g != ts.w {
+			t.Errorf("Error() = %q want %q", g, ts.w)
+		}
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	resp := &http.Response{Header: http.Header{
+		"X-Amz-Request-Id": {"656c76696e6727732072657175657374"},
+		"X-Amz-Id-2":       {"Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg=="},
+	}}
+	reqID, extID := RequestIDs(resp)
+	if reqID != "656c76696e6727732072657175657374" {
+		t.Errorf("reqID = %q", reqID)
+	}
+	if extID != "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==" {
+		t.Errorf("extID = %q", extID)
+	}
+
+	reqID, extID = RequestIDs(&http.Response{Header: http.Header{}})
+	if reqID != "" || extID != "" {
+		t.Errorf("got %q, %q want empty", reqID, extID)
+	}
+}
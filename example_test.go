@@ -70,3 +70,21 @@ func ExampleSign() {
 	}
 	fmt.Println(resp.StatusCode)
 }
+
+func ExampleRoundTripFunc() {
+	s := *s3.DefaultService
+	s.Transport = s3.RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		fmt.Println(r.Method, r.URL, strings.HasPrefix(r.Header.Get("Authorization"), "AWS AKID:"))
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+	})
+	client := s.Client(s3.Keys{AccessKey: "AKID", SecretKey: "secret"})
+	r, _ := http.NewRequest("PUT", "https://example.s3.amazonaws.com/foo", strings.NewReader("hello, world"))
+	resp, err := client.Do(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.StatusCode)
+	// Output:
+	// PUT https://example.s3.amazonaws.com/foo true
+	// 200
+}
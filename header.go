@@ -0,0 +1,194 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetIfNoneMatch makes r conditional on the object's ETag
+// differing from etag. S3 responds with 304 (Not Modified),
+// and no body, if it does not. Quotes are added to etag
+// if necessary.
+func SetIfNoneMatch(r *http.Request, etag string) {
+	r.Header.Set("If-None-Match", quoteETag(etag))
+}
+
+// SetIfModifiedSince makes r conditional on the object having
+// been modified after t. S3 responds with 304 (Not Modified),
+// and no body, if it has not.
+func SetIfModifiedSince(r *http.Request, t time.Time) {
+	r.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// SSES3 asks S3 to encrypt the object uploaded by r
+// with keys managed by S3 (SSE-S3).
+func SSES3(r *http.Request) {
+	r.Header.Set("X-Amz-Server-Side-Encryption", "AES256")
+}
+
+// SSEKMS asks S3 to encrypt the object uploaded by r with
+// the AWS KMS key keyID (SSE-KMS). If keyID is empty, S3
+// uses the account's default KMS key.
+func SSEKMS(r *http.Request, keyID string) {
+	r.Header.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+	if keyID != "" {
+		r.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", keyID)
+	}
+}
+
+// SSECustomerKey asks S3 to encrypt the object uploaded by r,
+// or to decrypt the object fetched by r, with key, a 256-bit
+// AES key supplied by the caller (SSE-C). The same key must
+// be sent with every request that reads the object.
+func SSECustomerKey(r *http.Request, key []byte) {
+	sum := md5.Sum(key)
+	r.Header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", "AES256")
+	r.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key", base64.StdEncoding.EncodeToString(key))
+	r.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// StorageClass is an S3 storage class.
+type StorageClass string
+
+// Storage classes.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/storage-class-intro.html.
+const (
+	StorageStandard           StorageClass = "STANDARD"
+	StorageReducedRedundancy  StorageClass = "REDUCED_REDUNDANCY"
+	StorageStandardIA         StorageClass = "STANDARD_IA"
+	StorageOneZoneIA          StorageClass = "ONEZONE_IA"
+	StorageIntelligentTiering StorageClass = "INTELLIGENT_TIERING"
+	StorageGlacier            StorageClass = "GLACIER"
+	StorageGlacierIR          StorageClass = "GLACIER_IR"
+	StorageDeepArchive        StorageClass = "DEEP_ARCHIVE"
+)
+
+// SetStorageClass sets the storage class of the object uploaded by r.
+func SetStorageClass(r *http.Request, c StorageClass) {
+	r.Header.Set("X-Amz-Storage-Class", string(c))
+}
+
+// ACL is an S3 canned access control list.
+type ACL string
+
+// Canned ACLs.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html#canned-acl.
+const (
+	ACLPrivate                ACL = "private"
+	ACLPublicRead             ACL = "public-read"
+	ACLPublicReadWrite        ACL = "public-read-write"
+	ACLAuthenticatedRead      ACL = "authenticated-read"
+	ACLAWSExecRead            ACL = "aws-exec-read"
+	ACLBucketOwnerRead        ACL = "bucket-owner-read"
+	ACLBucketOwnerFullControl ACL = "bucket-owner-full-control"
+	ACLLogDeliveryWrite       ACL = "log-delivery-write"
+)
+
+// SetACL sets the canned ACL of the object or bucket created by r.
+func SetACL(r *http.Request, a ACL) {
+	r.Header.Set("X-Amz-Acl", string(a))
+}
+
+// SetWebsiteRedirect makes the object uploaded by r, in a bucket
+// configured as a website, redirect to target, either another
+// object in the bucket, such as "/other.html", or an external URL.
+func SetWebsiteRedirect(r *http.Request, target string) {
+	r.Header.Set("X-Amz-Website-Redirect-Location", target)
+}
+
+// SetResponseHeaders sets the headers in m, such as Cache-Control,
+// Content-Disposition, or Expires, on r. S3 stores them with the
+// object uploaded by r and sends them back whenever the object
+// is fetched.
+func SetResponseHeaders(r *http.Request, m map[string]string) {
+	for k, v := range m {
+		r.Header.Set(k, v)
+	}
+}
+
+// LockMode is an S3 Object Lock retention mode.
+type LockMode string
+
+// Retention modes.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/object-lock-overview.html.
+const (
+	LockGovernance LockMode = "GOVERNANCE"
+	LockCompliance LockMode = "COMPLIANCE"
+)
+
+// SetRetention protects the object version uploaded by r from
+// being overwritten or deleted until time until, in the given mode.
+// The bucket must have Object Lock enabled.
+func SetRetention(r *http.Request, mode LockMode, until time.Time) {
+	r.Header.Set("X-Amz-Object-Lock-Mode", string(mode))
+	r.Header.Set("X-Amz-Object-Lock-Retain-Until-Date", until.UTC().Format(time.RFC3339))
+}
+
+// SetLegalHold places a legal hold on the object version uploaded
+// by r, if on is true, protecting it until the hold is removed.
+// The bucket must have Object Lock enabled.
+func SetLegalHold(r *http.Request, on bool) {
+	v := "OFF"
+	if on {
+		v = "ON"
+	}
+	r.Header.Set("X-Amz-Object-Lock-Legal-Hold", v)
+}
+
+// SetRequesterPays acknowledges that the requester, rather than
+// the bucket owner, pays for r. Requests to a Requester Pays
+// bucket without it fail with 403 (Forbidden).
+func SetRequesterPays(r *http.Request) {
+	r.Header.Set("X-Amz-Request-Payer", "requester")
+}
+
+const metaPrefix = "X-Amz-Meta-"
+
+// SetMetadata adds the user-defined metadata in m to the object
+// uploaded by r, as headers with prefix X-Amz-Meta-. Keys are
+// case-insensitive; S3 stores them in lower case. Values that
+// are not printable ASCII are encoded as in RFC 2047.
+func SetMetadata(r *http.Request, m map[string]string) {
+	for k, v := range m {
+		r.Header.Set(metaPrefix+k, encodeMetaValue(v))
+	}
+}
+
+// Metadata returns the user-defined metadata of the object in
+// resp, with the X-Amz-Meta- prefix removed and keys in lower case.
+// Values encoded as in RFC 2047 are decoded.
+func Metadata(resp *http.Response) map[string]string {
+	m := make(map[string]string)
+	var dec mime.WordDecoder
+	for k, vs := range resp.Header {
+		if len(vs) == 0 || !strings.HasPrefix(k, metaPrefix) {
+			continue
+		}
+		v, err := dec.DecodeHeader(vs[0])
+		if err != nil {
+			v = vs[0]
+		}
+		m[strings.ToLower(k[len(metaPrefix):])] = v
+	}
+	return m
+}
+
+func encodeMetaValue(v string) string {
+	for i := 0; i < len(v); i++ {
+		if v[i] < ' ' || v[i] > '~' {
+			return mime.QEncoding.Encode("utf-8", v)
+		}
+	}
+	return v
+}
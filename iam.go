@@ -0,0 +1,99 @@
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Base URLs of the EC2 instance metadata service and the
+// ECS task metadata endpoint. Variables for testing.
+var (
+	metadataURL = "http://169.254.169.254"
+	ecsURL      = "http://169.254.170.2"
+)
+
+var metadataClient = &http.Client{Timeout: 5 * time.Second}
+
+// IAMKeys returns temporary keys for the IAM role of the
+// current EC2 instance or ECS task. The returned Keys
+// have Expires set to the time at which they expire.
+//
+// If environment variable AWS_CONTAINER_CREDENTIALS_RELATIVE_URI
+// is set, IAMKeys queries the ECS credentials endpoint.
+// Otherwise it queries the EC2 instance metadata service,
+// using a session token (IMDSv2).
+func IAMKeys() (*Keys, error) {
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		return fetchIAMKeys(ecsURL+uri, "")
+	}
+
+	r, _ := http.NewRequest("PUT", metadataURL+"/latest/api/token", nil)
+	r.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	token, err := metadataGet(r)
+	if err != nil {
+		return nil, err
+	}
+
+	const credsPath = "/latest/meta-data/iam/security-credentials/"
+	r, _ = http.NewRequest("GET", metadataURL+credsPath, nil)
+	r.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+	roles, err := metadataGet(r)
+	if err != nil {
+		return nil, err
+	}
+	a := strings.Fields(roles)
+	if len(a) == 0 {
+		return nil, errors.New("s3: no IAM role attached to instance")
+	}
+	return fetchIAMKeys(metadataURL+credsPath+a[0], token)
+}
+
+func fetchIAMKeys(url, token string) (*Keys, error) {
+	r, _ := http.NewRequest("GET", url, nil)
+	if token != "" {
+		r.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+	}
+	body, err := metadataGet(r)
+	if err != nil {
+		return nil, err
+	}
+	var v struct {
+		Code            string
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return nil, err
+	}
+	if v.Code != "" && v.Code != "Success" {
+		return nil, fmt.Errorf("s3: instance metadata returned code %q", v.Code)
+	}
+	k := &Keys{
+		AccessKey:     v.AccessKeyID,
+		SecretKey:     v.SecretAccessKey,
+		SecurityToken: v.Token,
+		Expires:       v.Expiration,
+	}
+	return k, nil
+}
+
+func metadataGet(r *http.Request) (string, error) {
+	resp, err := metadataClient.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("s3: instance metadata %s: unwanted http status %d", r.URL.Path, resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	return strings.TrimSpace(string(b)), err
+}
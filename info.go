@@ -0,0 +1,50 @@
+package s3
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info describes an S3 object, as reported in the headers
+// of a response to a HEAD or GET request.
+type Info struct {
+	Size         int64
+	ContentType  string
+	ETag         string // without quotes
+	LastModified time.Time
+	StorageClass StorageClass // empty means StorageStandard
+	VersionID    string       // empty if versioning is not enabled
+	Metadata     map[string]string
+}
+
+// ObjectInfo returns the description of the object in resp.
+// It returns an error if header Content-Length or Last-Modified
+// is present but malformed.
+func ObjectInfo(resp *http.Response) (*Info, error) {
+	h := resp.Header
+	info := &Info{
+		Size:         resp.ContentLength,
+		ContentType:  h.Get("Content-Type"),
+		ETag:         strings.Trim(h.Get("Etag"), `"`),
+		StorageClass: StorageClass(h.Get("X-Amz-Storage-Class")),
+		VersionID:    h.Get("X-Amz-Version-Id"),
+		Metadata:     Metadata(resp),
+	}
+	if s := h.Get("Content-Length"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		info.Size = n
+	}
+	if s := h.Get("Last-Modified"); s != "" {
+		t, err := http.ParseTime(s)
+		if err != nil {
+			return nil, err
+		}
+		info.LastModified = t
+	}
+	return info, nil
+}
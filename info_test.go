@@ -0,0 +1,45 @@
+package s3
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestObjectInfo(t *testing.T) {
+	resp := &http.Response{
+		ContentLength: -1,
+		Header: http.Header{
+			"Content-Length":      {"434234"},
+			"Content-Type":        {"text/plain"},
+			"Etag":                {`"fba9dede5f27731c9771645a39863328"`},
+			"Last-Modified":       {"Sun, 01 Jan 2006 12:00:00 GMT"},
+			"X-Amz-Storage-Class": {"STANDARD_IA"},
+			"X-Amz-Version-Id":    {"3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY"},
+			"X-Amz-Meta-Family":   {"Muntz"},
+			"X-Amz-Request-Id":    {"318BC8BC148832E5"},
+		},
+	}
+	got, err := ObjectInfo(resp)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	w := &Info{
+		Size:         434234,
+		ContentType:  "text/plain",
+		ETag:         "fba9dede5f27731c9771645a39863328",
+		LastModified: time.Date(2006, 1, 1, 12, 0, 0, 0, time.UTC),
+		StorageClass: StorageStandardIA,
+		VersionID:    "3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY",
+		Metadata:     map[string]string{"family": "Muntz"},
+	}
+	if !reflect.DeepEqual(got, w) {
+		t.Errorf("ObjectInfo = %+v want %+v", got, w)
+	}
+
+	resp.Header.Set("Last-Modified", "yesterday")
+	if _, err := ObjectInfo(resp); err == nil {
+		t.Error("expected error for malformed Last-Modified")
+	}
+}
@@ -0,0 +1,95 @@
+package s3
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvKeys returns the keys in environment variables
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and, if set,
+// AWS_SESSION_TOKEN. It returns an error if either of the
+// first two is missing.
+func EnvKeys() (*Keys, error) {
+	k := &Keys{
+		AccessKey:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:     os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SecurityToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if k.AccessKey == "" {
+		return nil, errors.New("s3: AWS_ACCESS_KEY_ID not set")
+	}
+	if k.SecretKey == "" {
+		return nil, errors.New("s3: AWS_SECRET_ACCESS_KEY not set")
+	}
+	return k, nil
+}
+
+// FileKeys returns the keys for the named profile in the shared
+// credentials file used by the AWS command line tools. The file is
+// named by environment variable AWS_SHARED_CREDENTIALS_FILE, or
+// defaults to ~/.aws/credentials. If profile is empty, "default"
+// is used.
+func FileKeys(profile string) (*Keys, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	name := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if name == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		name = filepath.Join(home, ".aws", "credentials")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		k       Keys
+		found   bool
+		section string
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == profile {
+				found = true
+			}
+		case section == profile:
+			i := strings.Index(line, "=")
+			if i < 0 {
+				continue
+			}
+			v := strings.TrimSpace(line[i+1:])
+			switch strings.TrimSpace(line[:i]) {
+			case "aws_access_key_id":
+				k.AccessKey = v
+			case "aws_secret_access_key":
+				k.SecretKey = v
+			case "aws_session_token":
+				k.SecurityToken = v
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("s3: profile %q not found in %s", profile, name)
+	}
+	if k.AccessKey == "" || k.SecretKey == "" {
+		return nil, fmt.Errorf("s3: profile %q in %s is missing aws_access_key_id or aws_secret_access_key", profile, name)
+	}
+	return &k, nil
+}
@@ -0,0 +1,115 @@
+package s3
+
+// See
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/HTTPPOSTForms.html
+// and
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html.
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Condition is a condition in a POST policy, which every form
+// submitted with the policy must satisfy.
+type Condition []interface{}
+
+// Eq returns a condition that form field field must be equal
+// to value.
+func Eq(field, value string) Condition {
+	return Condition{"eq", "$" + field, value}
+}
+
+// StartsWith returns a condition that form field field must
+// begin with prefix. An empty prefix allows any value.
+func StartsWith(field, prefix string) Condition {
+	return Condition{"starts-with", "$" + field, prefix}
+}
+
+// ContentLengthRange returns a condition that the uploaded
+// file must be between min and max bytes long, inclusive.
+func ContentLengthRange(min, max int64) Condition {
+	return Condition{"content-length-range", min, max}
+}
+
+// PostForm holds what an HTML form needs to upload a file
+// directly to S3.
+type PostForm struct {
+	// URL is the form's action.
+	URL string
+
+	// Fields are the form fields that must be submitted along
+	// with the file. Fields required by conditions other than
+	// Eq, such as "key" under a StartsWith condition, are left
+	// for the form to fill in.
+	Fields map[string]string
+}
+
+type postPolicy struct {
+	Expiration string      `json:"expiration"`
+	Conditions []Condition `json:"conditions"`
+}
+
+// PresignPost returns a form for uploading a file to bucket with
+// a browser, by means of a POST policy signed with keys k. Every
+// submission of the form must satisfy conditions, and must happen
+// before the policy expires, after the given duration.
+//
+// The signature version is the same one used by Sign.
+func (s *Service) PresignPost(bucket string, conditions []Condition, k Keys, expires time.Duration) (*PostForm, error) {
+	if s.Version == 4 && expires > maxExpires4 {
+		return nil, errors.New("s3: POST policy expiration exceeds seven days")
+	}
+	t := timeNow().UTC()
+	f := &PostForm{URL: s.URL(bucket, ""), Fields: map[string]string{}}
+	p := postPolicy{
+		Expiration: t.Add(expires).Format("2006-01-02T15:04:05.000Z"),
+		Conditions: []Condition{Eq("bucket", bucket)},
+	}
+	for _, c := range conditions {
+		if len(c) == 3 && c[0] == "eq" {
+			if field, ok := c[1].(string); ok && strings.HasPrefix(field, "$") {
+				if v, ok := c[2].(string); ok {
+					f.Fields[field[1:]] = v
+				}
+			}
+		}
+		p.Conditions = append(p.Conditions, c)
+	}
+	if k.SecurityToken != "" {
+		f.Fields["x-amz-security-token"] = k.SecurityToken
+		p.Conditions = append(p.Conditions, Eq("x-amz-security-token", k.SecurityToken))
+	}
+	if s.Version == 4 {
+		f.Fields["x-amz-algorithm"] = algorithm4
+		f.Fields["x-amz-credential"] = k.AccessKey + "/" + s.scope(t)
+		f.Fields["x-amz-date"] = t.Format(timeFormat4)
+		for _, name := range []string{"x-amz-algorithm", "x-amz-credential", "x-amz-date"} {
+			p.Conditions = append(p.Conditions, Eq(name, f.Fields[name]))
+		}
+	} else {
+		f.Fields["AWSAccessKeyId"] = k.AccessKey
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	policy := base64.StdEncoding.EncodeToString(b)
+	f.Fields["policy"] = policy
+	if s.Version == 4 {
+		key := signingKey(k.SecretKey, t.Format(dateFormat4), s.region())
+		f.Fields["x-amz-signature"] = hex.EncodeToString(hmacSHA256(key, []byte(policy)))
+	} else {
+		h := hmac.New(sha1.New, []byte(k.SecretKey))
+		h.Write([]byte(policy))
+		f.Fields["signature"] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return f, nil
+}
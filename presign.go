@@ -0,0 +1,124 @@
+package s3
+
+// See
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html#RESTAuthenticationQueryStringAuth
+// and
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html.
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Version 4 presigned URLs can be valid for at most seven days.
+const maxExpires4 = 7 * 24 * time.Hour
+
+// PresignGet returns a URL for a GET request of u that carries
+// its own signature, made with keys k. Anyone holding the URL can
+// fetch the object without credentials until the URL expires,
+// after the given duration.
+//
+// The signature version is the same one used by Sign.
+func (s *Service) PresignGet(u string, k Keys, expires time.Duration) (string, error) {
+	return s.Presign("GET", u, nil, k, expires)
+}
+
+// PresignPut returns a URL for a PUT request of u that carries its
+// own signature, made with keys k, and expires after the given
+// duration. The entries of h, such as Content-Type or X-Amz-Acl,
+// are included in the signature, so the client must send each of
+// them, with the same values, along with its PUT request.
+//
+// The signature version is the same one used by Sign.
+func (s *Service) PresignPut(u string, k Keys, expires time.Duration, h http.Header) (string, error) {
+	return s.Presign("PUT", u, h, k, expires)
+}
+
+// Presign returns a URL for a request of u with the given method
+// that carries its own signature, made with keys k, and expires
+// after the given duration. The entries of h are included in the
+// signature, so the client must send each of them, with the same
+// values, along with its request. This is how a URL can require,
+// say, X-Amz-Server-Side-Encryption on an upload, or supply the
+// X-Amz-Server-Side-Encryption-Customer- headers needed to read
+// an object encrypted with SSE-C. A version 4 URL lists those
+// headers in its X-Amz-SignedHeaders parameter, subject to
+// s.SignedHeaders.
+//
+// The signature version is the same one used by Sign.
+func (s *Service) Presign(method, u string, h http.Header, k Keys, expires time.Duration) (string, error) {
+	r, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, vs := range h {
+		for _, v := range vs {
+			r.Header.Add(key, v)
+		}
+	}
+	if s.Version == 4 {
+		return s.presign4(r, k, expires)
+	}
+	return s.presign2(r, k, expires)
+}
+
+func (s *Service) presign2(r *http.Request, k Keys, expires time.Duration) (string, error) {
+	exp := strconv.FormatInt(timeNow().Add(expires).Unix(), 10)
+	if k.SecurityToken != "" {
+		r.Header.Set("X-Amz-Security-Token", k.SecurityToken)
+	}
+	r.Header.Set("Date", exp) // Expires takes the place of Date
+	h := hmac.New(sha1.New, []byte(k.SecretKey))
+	s.writeSigData(h, r)
+
+	v := url.Values{}
+	v.Set("AWSAccessKeyId", k.AccessKey)
+	v.Set("Expires", exp)
+	v.Set("Signature", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	if k.SecurityToken != "" {
+		v.Set("x-amz-security-token", k.SecurityToken)
+	}
+	addQuery(r.URL, v.Encode())
+	return r.URL.String(), nil
+}
+
+func (s *Service) presign4(r *http.Request, k Keys, expires time.Duration) (string, error) {
+	if expires > maxExpires4 {
+		return "", errors.New("s3: presigned URL expiration exceeds seven days")
+	}
+	t := timeNow().UTC()
+	scope := s.scope(t)
+	v := url.Values{}
+	v.Set("X-Amz-Algorithm", algorithm4)
+	v.Set("X-Amz-Credential", k.AccessKey+"/"+scope)
+	v.Set("X-Amz-Date", t.Format(timeFormat4))
+	v.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	v.Set("X-Amz-SignedHeaders", s.writeCanonicalHeaders(ioutil.Discard, r))
+	if k.SecurityToken != "" {
+		v.Set("X-Amz-Security-Token", k.SecurityToken)
+	}
+	addQuery(r.URL, v.Encode())
+
+	var creq, sts bytes.Buffer
+	s.writeCanonicalRequest(&creq, r, unsignedPayload)
+	writeStringToSign(&sts, t, scope, creq.Bytes())
+	key := signingKey(k.SecretKey, t.Format(dateFormat4), s.region())
+	addQuery(r.URL, "X-Amz-Signature="+hex.EncodeToString(hmacSHA256(key, sts.Bytes())))
+	return r.URL.String(), nil
+}
+
+func addQuery(u *url.URL, q string) {
+	if u.RawQuery != "" {
+		q = u.RawQuery + "&" + q
+	}
+	u.RawQuery = q
+}
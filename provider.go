@@ -0,0 +1,47 @@
+package s3
+
+import (
+	"sync"
+	"time"
+)
+
+// A Provider supplies keys for signing requests.
+// Providers of temporary keys should refresh them
+// as necessary.
+type Provider interface {
+	Keys() (*Keys, error)
+}
+
+// StaticProvider is a Provider that always returns the same keys.
+type StaticProvider Keys
+
+// Keys returns p as a *Keys.
+func (p *StaticProvider) Keys() (*Keys, error) {
+	return (*Keys)(p), nil
+}
+
+// IAMProvider is a Provider that fetches keys using IAMKeys.
+// It caches the keys and fetches new ones shortly before they
+// expire. It is safe for concurrent use.
+type IAMProvider struct {
+	mu sync.Mutex
+	k  *Keys
+}
+
+// How long before expiration IAMProvider fetches new keys.
+const refreshWindow = 5 * time.Minute
+
+// Keys returns the cached keys, refreshing them first
+// if they are missing or about to expire.
+func (p *IAMProvider) Keys() (*Keys, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.k == nil || timeNow().Add(refreshWindow).After(p.k.Expires) {
+		k, err := IAMKeys()
+		if err != nil {
+			return nil, err
+		}
+		p.k = k
+	}
+	return p.k, nil
+}
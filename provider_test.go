@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticProvider(t *testing.T) {
+	k := exKeys
+	got, err := (*StaticProvider)(&k).Keys()
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if *got != exKeys {
+		t.Errorf("keys = %+v want %+v", *got, exKeys)
+	}
+}
+
+func TestIAMProviderRefresh(t *testing.T) {
+	var fetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/latest/api/token":
+			w.Write([]byte("token"))
+		case strings.HasSuffix(r.URL.Path, "/security-credentials/"):
+			w.Write([]byte("role"))
+		default:
+			fetches++
+			w.Write([]byte(iamCreds)) // expires 2012-04-27T22:39:16Z
+		}
+	}))
+	defer srv.Close()
+	defer func(s string) { metadataURL = s }(metadataURL)
+	metadataURL = srv.URL
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+
+	now := time.Date(2012, 4, 27, 20, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	p := new(IAMProvider)
+	for i := 0; i < 3; i++ {
+		if _, err := p.Keys(); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d want 1", fetches)
+	}
+
+	now = time.Date(2012, 4, 27, 22, 38, 0, 0, time.UTC) // near expiry
+	k, err := p.Keys()
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetches = %d want 2", fetches)
+	}
+	if w := iamExKeys(); *k != w {
+		t.Errorf("keys = %+v want %+v", *k, w)
+	}
+}
@@ -6,7 +6,9 @@
 //   s3cp url file
 //
 // The file does not need to be seekable or stat-able. You can use s3cp to
-// upload data of indeterminate length, such as from a pipe.
+// upload data of indeterminate length, such as from a pipe. If a download
+// fails, the partially written file is removed. Uploaded objects get
+// a Content-Type based on the file name extension.
 //
 // Examples:
 //   $ s3cp file.txt https://mybucket.s3.amazonaws.com/file.txt
@@ -23,7 +25,10 @@ package main
 import (
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/sqs/s3/s3util"
@@ -39,29 +44,65 @@ func main() {
 		os.Exit(1)
 	}
 
-	r, err := open(args[0])
-	if err != nil {
+	if err := cp(args[1], args[0]); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+}
 
-	w, err := create(args[1])
+// cp copies src to dst. If the copy fails, it cleans up
+// after itself as described at discard.
+func cp(dst, src string) error {
+	r, err := open(src)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return err
+	}
+	defer r.Close()
+
+	w, err := create(dst, src)
+	if err != nil {
+		return err
 	}
 
 	_, err = io.Copy(w, r)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		discard(dst, w)
+		return err
+	}
+
+	if f, ok := w.(*os.File); ok && isRegular(dst) {
+		err = f.Sync()
+		if err != nil {
+			discard(dst, w)
+			return err
+		}
 	}
 
 	err = w.Close()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		discard(dst, nil)
+		return err
 	}
+	return nil
+}
+
+// discard cleans up after a failed copy to s. If s is a regular
+// local file, it closes w, unless w is nil, and removes the partially
+// written file. Devices such as /dev/stdout are left alone, and an
+// upload is left unclosed, so that no truncated object is created.
+func discard(s string, w io.Closer) {
+	if isURL(s) || !isRegular(s) {
+		return
+	}
+	if w != nil {
+		w.Close()
+	}
+	os.Remove(s)
+}
+
+func isRegular(s string) bool {
+	fi, err := os.Stat(s)
+	return err == nil && fi.Mode().IsRegular()
 }
 
 func open(s string) (io.ReadCloser, error) {
@@ -71,9 +112,16 @@ func open(s string) (io.ReadCloser, error) {
 	return os.Open(s)
 }
 
-func create(s string) (io.WriteCloser, error) {
+// create creates s, to be filled with a copy of src.
+// An object uploaded to S3 gets a Content-Type guessed
+// from the file name extension of src.
+func create(s, src string) (io.WriteCloser, error) {
 	if isURL(s) {
-		return s3util.Create(s, nil, nil)
+		h := http.Header{}
+		if t := mime.TypeByExtension(path.Ext(src)); t != "" {
+			h.Set("Content-Type", t)
+		}
+		return s3util.Create(s, h, nil)
 	}
 	return os.Create(s)
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sqs/s3"
+	"github.com/sqs/s3/s3util"
+)
+
+// withTransport makes s3util send requests to f
+// for the rest of the test.
+func withTransport(t *testing.T, f s3.RoundTripFunc) {
+	c := s3util.DefaultConfig.Client
+	t.Cleanup(func() { s3util.DefaultConfig.Client = c })
+	s3util.DefaultConfig.Client = &http.Client{Transport: f}
+}
+
+// failReader returns its data, then an error.
+type failReader struct {
+	r io.Reader
+}
+
+func (f *failReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		err = errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestCpLocal(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src.txt"), filepath.Join(dir, "dst.txt")
+	if err := ioutil.WriteFile(src, []byte("hello, world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp(dst, src); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	b, err := ioutil.ReadFile(dst)
+	if err != nil || string(b) != "hello, world" {
+		t.Errorf("dst = %q, %v want %q", b, err, "hello, world")
+	}
+}
+
+func TestCpDownload(t *testing.T) {
+	withTransport(t, func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader("hello, world")),
+			Request:    r,
+		}, nil
+	})
+	dst := filepath.Join(t.TempDir(), "hello.txt")
+	if err := cp(dst, "https://mybucket.s3.amazonaws.com/hello.txt"); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	b, err := ioutil.ReadFile(dst)
+	if err != nil || string(b) != "hello, world" {
+		t.Errorf("dst = %q, %v want %q", b, err, "hello, world")
+	}
+}
+
+// A download that fails partway must not leave a partial file.
+func TestCpDownloadError(t *testing.T) {
+	withTransport(t, func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(&failReader{strings.NewReader("hello, wo")}),
+			Request:    r,
+		}, nil
+	})
+	dst := filepath.Join(t.TempDir(), "hello.txt")
+	if err := cp(dst, "https://mybucket.s3.amazonaws.com/hello.txt"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: Stat = %v", err)
+	}
+}
+
+func TestCpLocalError(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "dst")
+	// Opening a directory succeeds, but reading it fails.
+	if err := cp(dst, dir); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: Stat = %v", err)
+	}
+}
+
+func TestCreateContentType(t *testing.T) {
+	tests := []struct {
+		src, w string
+	}{
+		{"data.json", "application/json"},
+		{"data.unknownext", "application/octet-stream"},
+		{"data", "application/octet-stream"},
+	}
+	for _, ts := range tests {
+		var got string
+		withTransport(t, func(r *http.Request) (*http.Response, error) {
+			body := ""
+			if r.Method == "POST" && r.URL.RawQuery == "uploads" {
+				got = r.Header.Get("Content-Type")
+				body = `<UploadId>foo</UploadId>`
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Etag": {`"foo"`}},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		})
+		w, err := create("https://mybucket.s3.amazonaws.com/"+ts.src, ts.src)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if got != ts.w {
+			t.Errorf("%s: Content-Type = %q want %q", ts.src, got, ts.w)
+		}
+	}
+}
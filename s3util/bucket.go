@@ -0,0 +1,62 @@
+package s3util
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+)
+
+type createBucketConfiguration struct {
+	XMLName            xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CreateBucketConfiguration"`
+	LocationConstraint string
+}
+
+// CreateBucket creates the S3 bucket at url in the given region,
+// such as "eu-west-1". The request is signed for that region.
+// An empty region means us-east-1, whose buckets are created
+// without a location constraint. An HTTP status other than 200
+// is considered an error.
+//
+// If c is nil, CreateBucket uses DefaultConfig.
+func CreateBucket(url, region string, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	rc := *c
+	svc := *c.Service
+	svc.Region = region
+	rc.Service = &svc
+	var body []byte
+	if region != "" && region != "us-east-1" {
+		var err error
+		body, err = xml.Marshal(createBucketConfiguration{LocationConstraint: region})
+		if err != nil {
+			return err
+		}
+	}
+	r, err := http.NewRequest("PUT", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := rc.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	discard(resp)
+	return nil
+}
+
+// DeleteBucket deletes the S3 bucket at url, which must be empty.
+// An HTTP status other than 204 (No Content) is considered an error.
+//
+// If c is nil, DeleteBucket uses DefaultConfig.
+func DeleteBucket(url string, c *Config) error {
+	rc, err := Delete(url, c)
+	if err != nil {
+		return err
+	}
+	return rc.Close()
+}
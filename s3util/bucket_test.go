@@ -0,0 +1,78 @@
+package s3util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sqs/s3"
+)
+
+func TestCreateBucket(t *testing.T) {
+	tests := []struct {
+		region string
+		body   string
+		scope  string
+	}{
+		{"", "", "/us-east-1/s3/"},
+		{"us-east-1", "", "/us-east-1/s3/"},
+		{
+			"eu-west-1",
+			`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>eu-west-1</LocationConstraint></CreateBucketConfiguration>`,
+			"/eu-west-1/s3/",
+		},
+	}
+	for _, ts := range tests {
+		c := *DefaultConfig
+		c.Service = &s3.Service{Domain: "amazonaws.com", Version: 4}
+		c.Keys = &s3.Keys{AccessKey: "AKID", SecretKey: "secret"}
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Method != "PUT" {
+					t.Errorf("method = %q want PUT", req.Method)
+				}
+				b, _ := ioutil.ReadAll(req.Body)
+				if string(b) != ts.body {
+					t.Errorf("region %q: body = %q want %q", ts.region, b, ts.body)
+				}
+				if g := req.Header.Get("Authorization"); !strings.Contains(g, ts.scope) {
+					t.Errorf("region %q: Authorization = %q want scope %q", ts.region, g, ts.scope)
+				}
+				resp := &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+				}
+				return resp, nil
+			}),
+		}
+		if err := CreateBucket("https://johnsmith.s3.amazonaws.com/", ts.region, &c); err != nil {
+			t.Errorf("region %q: unexpected err %v", ts.region, err)
+		}
+	}
+}
+
+func TestDeleteBucket(t *testing.T) {
+	status := http.StatusNoContent
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != "DELETE" {
+				t.Errorf("method = %q want DELETE", req.Method)
+			}
+			resp := &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+			return resp, nil
+		}),
+	}
+	if err := DeleteBucket("https://johnsmith.s3.amazonaws.com/", &c); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	status = http.StatusConflict
+	if err := DeleteBucket("https://johnsmith.s3.amazonaws.com/", &c); err == nil {
+		t.Error("expected error for status 409")
+	}
+}
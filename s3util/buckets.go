@@ -0,0 +1,44 @@
+package s3util
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+)
+
+// Bucket describes an S3 bucket, as listed by ListBuckets.
+type Bucket struct {
+	Name         string
+	CreationDate time.Time
+}
+
+// ListBuckets returns the buckets owned by the sender of the
+// request, as given by c.Keys. The request goes to the root of
+// c.Service, as given by its URL method with an empty bucket.
+// An HTTP status other than 200 is considered an error.
+//
+// If c is nil, ListBuckets uses DefaultConfig.
+func ListBuckets(c *Config) ([]Bucket, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	r, err := http.NewRequest("GET", c.Service.URL("", ""), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRespError(resp)
+	}
+	defer discard(resp)
+	var result struct {
+		Buckets []Bucket `xml:"Buckets>Bucket"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Buckets, nil
+}
@@ -0,0 +1,62 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sqs/s3"
+)
+
+const listBucketsResult = `<?xml version="1.0" encoding="UTF-8"?>
+<ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Owner>
+    <ID>bcaf1ffd86f461ca5fb16fd081034f</ID>
+    <DisplayName>webfile</DisplayName>
+  </Owner>
+  <Buckets>
+    <Bucket>
+      <Name>quotes</Name>
+      <CreationDate>2006-02-03T16:45:09.000Z</CreationDate>
+    </Bucket>
+    <Bucket>
+      <Name>samples</Name>
+      <CreationDate>2006-02-03T16:41:58.000Z</CreationDate>
+    </Bucket>
+  </Buckets>
+</ListAllMyBucketsResult>`
+
+func TestListBuckets(t *testing.T) {
+	c := *DefaultConfig
+	c.Service = &s3.Service{Domain: "amazonaws.com", Region: "eu-west-1", Version: 4}
+	c.Keys = &s3.Keys{AccessKey: "AKID", SecretKey: "secret"}
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if g, w := req.Method+" "+req.URL.String(), "GET https://s3.eu-west-1.amazonaws.com/"; g != w {
+				t.Errorf("request = %q want %q", g, w)
+			}
+			if g, w := req.Header.Get("Authorization"), "/eu-west-1/s3/"; !strings.Contains(g, w) {
+				t.Errorf("Authorization = %q want scope %q", g, w)
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(listBucketsResult)),
+			}
+			return resp, nil
+		}),
+	}
+	got, err := ListBuckets(&c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	w := []Bucket{
+		{"quotes", time.Date(2006, 2, 3, 16, 45, 9, 0, time.UTC)},
+		{"samples", time.Date(2006, 2, 3, 16, 41, 58, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, w) {
+		t.Errorf("buckets = %v want %v", got, w)
+	}
+}
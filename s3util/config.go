@@ -4,14 +4,17 @@
 // and close the object.
 //
 // You must assign valid credentials to DefaultConfig.Keys before using
-// DefaultConfig. Be sure to close an io.WriteCloser returned by this package,
-// to flush buffers and complete the multipart upload process.
+// DefaultConfig, unless you only read public objects: requests made
+// with empty keys are sent unsigned. Be sure to close an io.WriteCloser
+// returned by this package, to flush buffers and complete the multipart
+// upload process.
 package s3util
 
 // TODO(kr): parse error responses; return structured data
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/sqs/s3"
 )
@@ -24,5 +27,51 @@ var DefaultConfig = &Config{
 type Config struct {
 	*s3.Service
 	*s3.Keys
-	*http.Client // if nil, uses http.DefaultClient
+	*http.Client       // if nil, uses http.DefaultClient
+	PartSize     int64 // multipart upload part size; if zero, uses 5MiB
+	Concurrency  int   // parts uploaded at once; if zero, uses 5
+
+	// Provider, if not nil, supplies the keys for each request
+	// in place of Keys, so that temporary keys can be refreshed
+	// while a long-running program uses this Config. Use it
+	// rather than a Client from s3.ProviderClient, which would
+	// sign each request a second time.
+	Provider s3.Provider
+}
+
+// keys returns the keys to sign a request with.
+// It returns s3.ErrExpired if they have expired.
+func (c *Config) keys() (*s3.Keys, error) {
+	k := c.Keys
+	if c.Provider != nil {
+		var err error
+		if k, err = c.Provider.Keys(); err != nil {
+			return nil, err
+		}
+	}
+	if k.Expired() {
+		return nil, s3.ErrExpired
+	}
+	return k, nil
+}
+
+// do signs r, as of now, and sends it with c.Client.
+func (c *Config) do(r *http.Request) (*http.Response, error) {
+	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	k, err := c.keys()
+	if err != nil {
+		return nil, err
+	}
+	c.Sign(r, *k)
+	// A version 4 signature reads the body, through GetBody, to hash it.
+	if r.GetBody != nil && r.Body != nil && r.Body != http.NoBody {
+		if r.Body, err = r.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(r)
 }
@@ -0,0 +1,106 @@
+package s3util
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sqs/s3"
+)
+
+type providerFunc func() (*s3.Keys, error)
+
+func (f providerFunc) Keys() (*s3.Keys, error) { return f() }
+
+func TestConfigProvider(t *testing.T) {
+	var calls int
+	var auth []string
+	c := *DefaultConfig
+	c.Keys = nil
+	c.Provider = providerFunc(func() (*s3.Keys, error) {
+		calls++
+		return &s3.Keys{AccessKey: "provided", SecretKey: "secret"}, nil
+	})
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			auth = append(auth, req.Header.Get("Authorization"))
+			body := ""
+			if req.Method == "POST" && req.URL.RawQuery == "uploads" {
+				body = `<UploadId>foo</UploadId>`
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Etag": {`"foo"`}},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}
+			return resp, nil
+		}),
+	}
+	r, err := Open("https://mybucket.s3.amazonaws.com/a.txt", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	r.Close()
+	w, err := Create("https://mybucket.s3.amazonaws.com/b.txt", nil, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if calls != len(auth) {
+		t.Errorf("Keys called %d times for %d requests", calls, len(auth))
+	}
+	for _, a := range auth {
+		if !strings.Contains(a, "provided") {
+			t.Errorf("Authorization = %q want keys from Provider", a)
+		}
+	}
+}
+
+func TestConfigProviderError(t *testing.T) {
+	want := errors.New("no keys")
+	c := *DefaultConfig
+	c.Provider = providerFunc(func() (*s3.Keys, error) { return nil, want })
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+			return nil, errors.New("unexpected request")
+		}),
+	}
+	if _, err := Open("https://mybucket.s3.amazonaws.com/a.txt", &c); err != want {
+		t.Errorf("Open err = %v want %v", err, want)
+	}
+	if _, err := Create("https://mybucket.s3.amazonaws.com/b.txt", nil, &c); err != want {
+		t.Errorf("Create err = %v want %v", err, want)
+	}
+}
+
+func TestConfigExpiredKeys(t *testing.T) {
+	exp := &s3.Keys{AccessKey: "AKID", SecretKey: "secret", Expires: time.Now().Add(-time.Minute)}
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+			return nil, errors.New("unexpected request")
+		}),
+	}
+	for _, ts := range []struct {
+		keys     *s3.Keys
+		provider s3.Provider
+	}{
+		{exp, nil},
+		{nil, (*s3.StaticProvider)(exp)},
+	} {
+		c.Keys, c.Provider = ts.keys, ts.provider
+		if _, err := Open("https://mybucket.s3.amazonaws.com/a.txt", &c); err != s3.ErrExpired {
+			t.Errorf("Open err = %v want %v", err, s3.ErrExpired)
+		}
+		if _, err := Create("https://mybucket.s3.amazonaws.com/b.txt", nil, &c); err != s3.ErrExpired {
+			t.Errorf("Create err = %v want %v", err, s3.ErrExpired)
+		}
+	}
+}
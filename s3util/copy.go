@@ -0,0 +1,72 @@
+package s3util
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Copy copies the S3 object named src, in the form "bucket/key",
+// to a new object at url, without transferring its contents
+// through the client. An HTTP status other than 200, or an error
+// document in the response body, is considered an error.
+//
+// If h is not nil, each of its entries is added to the HTTP request header.
+// If c is nil, Copy uses DefaultConfig.
+func Copy(url, src string, h http.Header, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	r, _ := http.NewRequest("PUT", url, nil)
+	for k := range h {
+		for _, v := range h[k] {
+			r.Header.Add(k, v)
+		}
+	}
+	r.Header.Set("X-Amz-Copy-Source", copySource(src))
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	defer resp.Body.Close()
+
+	// S3 can report a failed copy with status 200,
+	// so look for an Error document in the body.
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var v struct{ XMLName xml.Name }
+	if xml.Unmarshal(b, &v) == nil && v.XMLName.Local == "Error" {
+		e := new(respError)
+		e.r = resp
+		e.b.Write(b)
+		return e
+	}
+	return nil
+}
+
+// copySource returns src, in the form "bucket/key",
+// escaped for use in header X-Amz-Copy-Source. S3 decodes
+// the header, and may take a bare '+' for a space, so every
+// byte is escaped except '/' and the unreserved characters
+// A-Z, a-z, 0-9, '-', '.', '_', and '~'.
+func copySource(src string) string {
+	const hexDigits = "0123456789ABCDEF"
+	src = "/" + strings.TrimPrefix(src, "/")
+	var b []byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' || c == '/' {
+			b = append(b, c)
+		} else {
+			b = append(b, '%', hexDigits[c>>4], hexDigits[c&15])
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,66 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var copySourceTest = []struct {
+	src string
+	w   string
+}{
+	{"johnsmith/photos/puppy.jpg", "/johnsmith/photos/puppy.jpg"},
+	{"/johnsmith/photos/puppy.jpg", "/johnsmith/photos/puppy.jpg"},
+	{"johnsmith/my photos/puppy #1.jpg", "/johnsmith/my%20photos/puppy%20%231.jpg"},
+	{"dictionary/français/préfère", "/dictionary/fran%C3%A7ais/pr%C3%A9f%C3%A8re"},
+	{"b/a+b c", "/b/a%2Bb%20c"},
+	{"b/k&v=1:2;x,y@z$!'()*", "/b/k%26v%3D1%3A2%3Bx%2Cy%40z%24%21%27%28%29%2A"},
+	{"b/~user/file-1_2.txt", "/b/~user/file-1_2.txt"},
+}
+
+func TestCopySource(t *testing.T) {
+	for _, ts := range copySourceTest {
+		if g := copySource(ts.src); g != ts.w {
+			t.Errorf("copySource(%q) = %q want %q", ts.src, g, ts.w)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		ok     bool
+	}{
+		{200, `<CopyObjectResult><ETag>"foo"</ETag></CopyObjectResult>`, true},
+		{200, `<Error><Code>InternalError</Code></Error>`, false},
+		{404, `<Error><Code>NoSuchKey</Code></Error>`, false},
+	}
+	for i, ts := range tests {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Method != "PUT" {
+					t.Errorf("method = %q want PUT", req.Method)
+				}
+				if g, w := req.Header.Get("X-Amz-Copy-Source"), "/johnsmith/my%20puppy.jpg"; g != w {
+					t.Errorf("X-Amz-Copy-Source = %q want %q", g, w)
+				}
+				resp := &http.Response{
+					StatusCode: ts.status,
+					Body:       ioutil.NopCloser(strings.NewReader(ts.body)),
+				}
+				return resp, nil
+			}),
+		}
+		err := Copy("https://s3.amazonaws.com/foo/bar", "johnsmith/my puppy.jpg", nil, &c)
+		if ts.ok && err != nil {
+			t.Errorf("test %d: unexpected err %v", i, err)
+		}
+		if !ts.ok && err == nil {
+			t.Errorf("test %d: expected error", i)
+		}
+	}
+}
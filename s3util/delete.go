@@ -3,7 +3,6 @@ package s3util
 import (
 	"io"
 	"net/http"
-	"time"
 )
 
 // Delete deletes the S3 object at url. An HTTP status other than 204 (No
@@ -15,13 +14,7 @@ func Delete(url string, c *Config) (io.ReadCloser, error) {
 		c = DefaultConfig
 	}
 	r, _ := http.NewRequest("DELETE", url, nil)
-	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	c.Sign(r, *c.Keys)
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
-	}
-	resp, err := client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, err
 	}
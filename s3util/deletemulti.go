@@ -0,0 +1,93 @@
+package s3util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+)
+
+// S3 accepts at most this many keys in one multi-object delete request.
+const maxDeleteKeys = 1000
+
+// DeleteResult reports the outcome of DeleteMulti.
+type DeleteResult struct {
+	Deleted []string      // keys that were deleted
+	Errors  []DeleteError // keys that could not be deleted
+}
+
+// DeleteError describes a key that DeleteMulti could not delete.
+// For the meaning of these fields, see
+// http://docs.aws.amazon.com/AmazonS3/latest/API/multiobjectdeleteapi.html.
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+// DeleteMulti deletes the objects with the given keys from the
+// bucket at bucketURL, such as https://mybucket.s3.amazonaws.com/.
+// It sends one request per 1000 keys. An HTTP status other than
+// 200 is considered an error; keys that S3 could not delete are
+// reported in the result instead.
+//
+// If c is nil, DeleteMulti uses DefaultConfig.
+func DeleteMulti(bucketURL string, keys []string, c *Config) (*DeleteResult, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	res := new(DeleteResult)
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxDeleteKeys {
+			n = maxDeleteKeys
+		}
+		if err := deleteMulti(bucketURL, keys[:n], c, res); err != nil {
+			return res, err
+		}
+		keys = keys[n:]
+	}
+	return res, nil
+}
+
+func deleteMulti(bucketURL string, keys []string, c *Config, res *DeleteResult) error {
+	var doc struct {
+		XMLName xml.Name `xml:"Delete"`
+		Object  []struct{ Key string }
+	}
+	for _, k := range keys {
+		doc.Object = append(doc.Object, struct{ Key string }{k})
+	}
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(body)
+	r, err := http.NewRequest("POST", bucketURL+"?delete", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	defer discard(resp)
+
+	var result struct {
+		Deleted []struct{ Key string }
+		Error   []DeleteError
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	for _, d := range result.Deleted {
+		res.Deleted = append(res.Deleted, d.Key)
+	}
+	res.Errors = append(res.Errors, result.Error...)
+	return nil
+}
@@ -0,0 +1,77 @@
+package s3util
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDeleteMulti(t *testing.T) {
+	var keys []string
+	for i := 0; i < 1500; i++ {
+		keys = append(keys, fmt.Sprintf("key%04d", i))
+	}
+	var nreq int
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			nreq++
+			if req.Method != "POST" || req.URL.RawQuery != "delete" {
+				t.Errorf("request = %s %s", req.Method, req.URL)
+			}
+			b, _ := ioutil.ReadAll(req.Body)
+			sum := md5.Sum(b)
+			if g, w := req.Header.Get("Content-MD5"), base64.StdEncoding.EncodeToString(sum[:]); g != w {
+				t.Errorf("Content-MD5 = %q want %q", g, w)
+			}
+			var doc struct {
+				Object []struct{ Key string }
+			}
+			if err := xml.Unmarshal(b, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if w := []int{1000, 500}[nreq-1]; len(doc.Object) != w {
+				t.Errorf("request %d: %d keys want %d", nreq, len(doc.Object), w)
+			}
+
+			// Report the first key of each request as failed.
+			var out strings.Builder
+			out.WriteString("<DeleteResult>")
+			for i, o := range doc.Object {
+				if i == 0 {
+					fmt.Fprintf(&out, "<Error><Key>%s</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", o.Key)
+				} else {
+					fmt.Fprintf(&out, "<Deleted><Key>%s</Key></Deleted>", o.Key)
+				}
+			}
+			out.WriteString("</DeleteResult>")
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(out.String())),
+			}
+			return resp, nil
+		}),
+	}
+	res, err := DeleteMulti("https://johnsmith.s3.amazonaws.com/", keys, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if nreq != 2 {
+		t.Errorf("sent %d requests want 2", nreq)
+	}
+	if len(res.Deleted) != 1498 {
+		t.Errorf("deleted %d keys want 1498", len(res.Deleted))
+	}
+	w := []DeleteError{
+		{"key0000", "AccessDenied", "Access Denied"},
+		{"key1000", "AccessDenied", "Access Denied"},
+	}
+	if fmt.Sprint(res.Errors) != fmt.Sprint(w) {
+		t.Errorf("errors = %v want %v", res.Errors, w)
+	}
+}
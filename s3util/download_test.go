@@ -0,0 +1,158 @@
+package s3util
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// objectServer returns a transport serving the object data,
+// in whole for HEAD and in part for ranged GET requests.
+func objectServer(t *testing.T, data []byte, fail int64) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Length": {strconv.Itoa(len(data))}},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+		if req.Method == "HEAD" {
+			return resp, nil
+		}
+		var start, end int64
+		if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("bad Range %q", req.Header.Get("Range"))
+		}
+		if start == fail {
+			resp.StatusCode = 500
+			return resp, nil
+		}
+		if end >= int64(len(data)) {
+			end = int64(len(data)) - 1
+		}
+		resp.StatusCode = http.StatusPartialContent
+		resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data[start : end+1]))
+		return resp, nil
+	}
+}
+
+func TestDownloadParallel(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	c := *DefaultConfig
+	c.Client = &http.Client{Transport: objectServer(t, data, -1)}
+	f, err := ioutil.TempFile("", "s3util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	n, err := DownloadParallel("https://s3.amazonaws.com/foo/bar", f, 4, 64, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("size = %d want %d", n, len(data))
+	}
+	got, _ := ioutil.ReadFile(f.Name())
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded data differs")
+	}
+}
+
+func TestDownloadParallelError(t *testing.T) {
+	data := make([]byte, 1000)
+	var gets int32
+	srv := objectServer(t, data, 0)
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == "GET" {
+				atomic.AddInt32(&gets, 1)
+			}
+			return srv(req)
+		}),
+	}
+	f, err := ioutil.TempFile("", "s3util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := DownloadParallel("https://s3.amazonaws.com/foo/bar", f, 1, 10, &c); err == nil {
+		t.Error("expected error")
+	}
+	if n := atomic.LoadInt32(&gets); n != 1 {
+		t.Errorf("made %d GET requests after failure want 1", n)
+	}
+}
+
+// A server that ignores Range answers each chunk request with the
+// whole object, which must not end up at each chunk's offset.
+func TestDownloadParallelIgnoredRange(t *testing.T) {
+	const data = "01234567890123456789"
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Length": {strconv.Itoa(len(data))}},
+				Body:       ioutil.NopCloser(strings.NewReader(data)),
+			}
+			return resp, nil
+		}),
+	}
+	f, err := ioutil.TempFile("", "s3util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := DownloadParallel("https://s3.amazonaws.com/foo/bar", f, 2, 5, &c); err == nil {
+		got, _ := ioutil.ReadFile(f.Name())
+		t.Errorf("downloaded %q with no error", got)
+	}
+}
+
+func TestDownloadParallelCancel(t *testing.T) {
+	data := make([]byte, 20)
+	srv := objectServer(t, data, 0)
+	started := make(chan bool)
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			switch req.Header.Get("Range") {
+			case "bytes=0-9":
+				<-started // fail only once the other chunk is in flight
+			case "bytes=10-19":
+				close(started)
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-time.After(5 * time.Second):
+					t.Error("chunk request not cancelled")
+				}
+			}
+			return srv(req)
+		}),
+	}
+	f, err := ioutil.TempFile("", "s3util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = DownloadParallel("https://s3.amazonaws.com/foo/bar", f, 2, 10, &c)
+	if err == nil || strings.Contains(err.Error(), "canceled") {
+		t.Errorf("err = %v want the failed chunk's error", err)
+	}
+}
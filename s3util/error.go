@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 )
 
+// Error documents are small. Past this many bytes, it's cheaper
+// to close a response body than to read it so its connection can
+// be reused.
+const maxDrain = 64 * 1024
+
 type respError struct {
 	r *http.Response
 	b bytes.Buffer
@@ -15,7 +21,7 @@ type respError struct {
 func newRespError(r *http.Response) *respError {
 	e := new(respError)
 	e.r = r
-	io.Copy(&e.b, r.Body)
+	io.CopyN(&e.b, r.Body, maxDrain)
 	r.Body.Close()
 	return e
 }
@@ -27,3 +33,10 @@ func (e *respError) Error() string {
 		e.b.String(),
 	)
 }
+
+// discard reads what remains of r's body, up to maxDrain bytes,
+// and closes it, so the connection can go back to the pool.
+func discard(r *http.Response) {
+	io.CopyN(ioutil.Discard, r.Body, maxDrain)
+	r.Body.Close()
+}
@@ -0,0 +1,19 @@
+package s3util
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+)
+
+// MultipartETag returns the ETag S3 gives an object uploaded in
+// parts whose MD5 digests are partMD5s: the MD5 digest of the
+// concatenated part digests, followed by "-" and the number
+// of parts. It is not the MD5 digest of the object.
+func MultipartETag(partMD5s [][]byte) string {
+	h := md5.New()
+	for _, sum := range partMD5s {
+		h.Write(sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(partMD5s))
+}
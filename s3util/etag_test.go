@@ -0,0 +1,15 @@
+package s3util
+
+import (
+	"crypto/md5"
+	"testing"
+)
+
+func TestMultipartETag(t *testing.T) {
+	a := md5.Sum([]byte("hello, "))
+	b := md5.Sum([]byte("world"))
+	const w = "b6633538fad7ce9d4f91da4ad8fd45c3-2"
+	if g := MultipartETag([][]byte{a[:], b[:]}); g != w {
+		t.Errorf("MultipartETag = %q want %q", g, w)
+	}
+}
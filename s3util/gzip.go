@@ -0,0 +1,48 @@
+package s3util
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// CreateGzip is like Create, but it compresses the data written
+// with gzip and sets Content-Encoding: gzip on the object, so
+// clients that fetch it with Accept-Encoding: gzip get the
+// original data back.
+//
+// The compressed size isn't known until all the data has been
+// written, so CreateGzip streams it in parts, as Create does,
+// rather than buffer the whole object to send it in a single
+// request. That holds memory down to the part buffers, at the cost
+// of an ETag that is not the MD5 digest of the compressed object.
+//
+// If c is nil, CreateGzip uses DefaultConfig.
+func CreateGzip(url string, h http.Header, c *Config) (io.WriteCloser, error) {
+	h2 := http.Header{}
+	for k, v := range h {
+		h2[k] = v
+	}
+	h2.Set("Content-Encoding", "gzip")
+	w, err := Create(url, h2, c)
+	if err != nil {
+		return nil, err
+	}
+	return &gzipWriter{gzip.NewWriter(w), w}, nil
+}
+
+type gzipWriter struct {
+	*gzip.Writer
+	w io.WriteCloser
+}
+
+// Close flushes the compressed data and completes the upload.
+// The upload is closed even if flushing fails, so that a failed
+// upload is aborted, and the first error is returned.
+func (g *gzipWriter) Close() error {
+	err := g.Writer.Close()
+	if err1 := g.w.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package s3util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCreateGzip(t *testing.T) {
+	var mu sync.Mutex
+	parts := map[int][]byte{}
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var s string
+			switch q := req.URL.Query(); {
+			case req.Method == "PUT":
+				b, _ := ioutil.ReadAll(req.Body)
+				n, _ := strconv.Atoi(q.Get("partNumber"))
+				mu.Lock()
+				parts[n] = b
+				mu.Unlock()
+			case req.Method == "POST" && q["uploads"] != nil:
+				if g, w := req.Header.Get("Content-Encoding"), "gzip"; g != w {
+					t.Errorf("Content-Encoding = %q want %q", g, w)
+				}
+				if g, w := req.Header.Get("Content-Type"), "text/plain"; g != w {
+					t.Errorf("Content-Type = %q want %q", g, w)
+				}
+				s = `<UploadId>foo</UploadId>`
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(s)),
+				Header:     http.Header{"Etag": {`"foo"`}},
+			}
+			return resp, nil
+		}),
+	}
+	h := http.Header{"Content-Type": {"text/plain"}}
+	w, err := CreateGzip("https://s3.amazonaws.com/foo/bar.txt", h, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if h.Get("Content-Encoding") != "" {
+		t.Error("CreateGzip modified the caller's header")
+	}
+	data := strings.Repeat("all work and no play makes jack a dull boy\n", 1e5)
+	if _, err := io.Copy(w, strings.NewReader(data)); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+
+	var stored []byte
+	for i := 1; i <= len(parts); i++ {
+		stored = append(stored, parts[i]...)
+	}
+	if len(stored) >= len(data) {
+		t.Errorf("stored %d bytes, not less than the %d written", len(stored), len(data))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if string(got) != data {
+		t.Error("decompressed object does not match the data written")
+	}
+}
+
+type failWriter struct {
+	closed bool
+}
+
+func (w *failWriter) Write(p []byte) (int, error) { return 0, errors.New("write failed") }
+
+func (w *failWriter) Close() error {
+	w.closed = true
+	return errors.New("close failed")
+}
+
+// Once a part has failed, writes to the upload fail too, but the
+// upload must still be closed, so that it is aborted.
+func TestCreateGzipCloseError(t *testing.T) {
+	fw := new(failWriter)
+	g := &gzipWriter{gzip.NewWriter(fw), fw}
+	err := g.Close()
+	if !fw.closed {
+		t.Error("upload not closed")
+	}
+	if err == nil || err.Error() != "write failed" {
+		t.Errorf("err = %v want write failed", err)
+	}
+}
@@ -0,0 +1,52 @@
+package s3util
+
+import (
+	"net/http"
+)
+
+// Head requests the headers of the S3 object at url, such as
+// Content-Length, ETag, and Last-Modified, without fetching its
+// contents. An HTTP status other than 200 is considered an error.
+//
+// If c is nil, Head uses DefaultConfig.
+func Head(url string, c *Config) (http.Header, error) {
+	resp, err := head(url, c)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRespError(resp)
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// Exists reports whether the S3 object at url exists.
+// A 404 Not Found response means the object does not exist;
+// any other non-2xx status, such as 403 Forbidden, is an error,
+// since it says nothing about the object's existence.
+//
+// If c is nil, Exists uses DefaultConfig.
+func Exists(url string, c *Config) (bool, error) {
+	resp, err := head(url, c)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case resp.StatusCode/100 == 2:
+		resp.Body.Close()
+		return true, nil
+	case resp.StatusCode == 404:
+		resp.Body.Close()
+		return false, nil
+	}
+	return false, newRespError(resp)
+}
+
+func head(url string, c *Config) (*http.Response, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	r, _ := http.NewRequest("HEAD", url, nil)
+	return c.do(r)
+}
@@ -0,0 +1,23 @@
+package s3util
+
+// Move moves the S3 object named src, in the form "bucket/key",
+// to url, by copying it there and then deleting src, which is
+// at srcURL. S3 has no way to rename an object. If the copy fails,
+// src is left as it is; if only the delete fails, the object exists
+// in both places.
+//
+// If c is nil, Move uses DefaultConfig.
+func Move(url, src, srcURL string, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	if err := Copy(url, src, nil, c); err != nil {
+		return err
+	}
+	r, err := Delete(srcURL, c)
+	if err != nil {
+		return err
+	}
+	r.Close()
+	return nil
+}
@@ -0,0 +1,50 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	for _, copyStatus := range []int{200, 403} {
+		var reqs []string
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				reqs = append(reqs, req.Method+" "+req.URL.String())
+				status := copyStatus
+				if req.Method == "DELETE" {
+					status = http.StatusNoContent
+				}
+				resp := &http.Response{
+					StatusCode: status,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+				return resp, nil
+			}),
+		}
+		err := Move(
+			"https://johnsmith.s3.amazonaws.com/new.jpg",
+			"oldbucket/photos/old.jpg",
+			"https://s3.eu-west-1.amazonaws.com/oldbucket/photos/old.jpg",
+			&c,
+		)
+		want := []string{
+			"PUT https://johnsmith.s3.amazonaws.com/new.jpg",
+			"DELETE https://s3.eu-west-1.amazonaws.com/oldbucket/photos/old.jpg",
+		}
+		if copyStatus != 200 {
+			if err == nil {
+				t.Error("expected error when copy fails")
+			}
+			want = want[:1] // source must remain
+		} else if err != nil {
+			t.Error("unexpected err", err)
+		}
+		if strings.Join(reqs, "\n") != strings.Join(want, "\n") {
+			t.Errorf("copy status %d: requests %q want %q", copyStatus, reqs, want)
+		}
+	}
+}
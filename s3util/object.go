@@ -0,0 +1,104 @@
+package s3util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Object is an S3 object whose size and ETag are known before its
+// contents are read. Read fetches the contents on first use, and
+// ReadAt fetches only the bytes asked for. Both require the object
+// to still have the ETag it had when it was opened, so its contents
+// don't change underfoot.
+//
+// ReadAt may be called concurrently, but not with Read.
+type Object struct {
+	Size   int64       // length of the contents, in bytes
+	ETag   string      // ETag value, without double quotes
+	Header http.Header // from the HEAD response
+
+	url  string
+	c    *Config
+	body io.ReadCloser
+}
+
+// OpenObject requests the headers of the S3 object at url, as Head
+// does, and returns an Object for reading its contents.
+//
+// If c is nil, OpenObject uses DefaultConfig.
+func OpenObject(url string, c *Config) (*Object, error) {
+	h, err := Head(url, c)
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	o := &Object{
+		Size:   size,
+		ETag:   strings.Trim(h.Get("Etag"), `"`),
+		Header: h,
+		url:    url,
+		c:      c,
+	}
+	return o, nil
+}
+
+func (o *Object) header() http.Header {
+	h := http.Header{}
+	if o.ETag != "" {
+		h.Set("If-Match", `"`+o.ETag+`"`)
+	}
+	return h
+}
+
+func (o *Object) Read(p []byte) (int, error) {
+	if o.body == nil {
+		resp, err := open(context.Background(), o.url, o.header(), 200, o.c)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	return o.body.Read(p)
+}
+
+// ReadAt reads len(p) bytes starting at offset off, with a request
+// for just that range of the object. A response other than 206
+// (Partial Content) for that range is an error.
+func (o *Object) ReadAt(p []byte, off int64) (int, error) {
+	if off >= o.Size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := min(off+int64(len(p)), o.Size)
+	h := o.header()
+	h.Set("Range", byteRange(off, end-1))
+	resp, err := open(context.Background(), o.url, h, http.StatusPartialContent, o.c)
+	if err != nil {
+		return 0, err
+	}
+	defer discard(resp)
+	if err := checkContentRange(resp, off, end-1); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(resp.Body, p[:end-off])
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Close closes the body fetched by Read, if any.
+func (o *Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	return o.body.Close()
+}
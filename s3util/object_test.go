@@ -0,0 +1,128 @@
+package s3util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestObject(t *testing.T) {
+	const data = "0123456789"
+	var mu sync.Mutex
+	var reqs []string
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			reqs = append(reqs, req.Method+" "+req.Header.Get("Range"))
+			mu.Unlock()
+			if req.Method != "HEAD" {
+				if g, w := req.Header.Get("If-Match"), `"abc"`; g != w {
+					t.Errorf("If-Match = %q want %q", g, w)
+				}
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Header: http.Header{
+					"Content-Length": {strconv.Itoa(len(data))},
+					"Etag":           {`"abc"`},
+				},
+				Body: ioutil.NopCloser(strings.NewReader("")),
+			}
+			switch {
+			case req.Method == "HEAD":
+			case req.Header.Get("Range") != "":
+				var start, end int
+				if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+					t.Fatal(err)
+				}
+				resp.StatusCode = http.StatusPartialContent
+				resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+				resp.Body = ioutil.NopCloser(strings.NewReader(data[start : end+1]))
+			default:
+				resp.Body = ioutil.NopCloser(strings.NewReader(data))
+			}
+			return resp, nil
+		}),
+	}
+	o, err := OpenObject("https://johnsmith.s3.amazonaws.com/digits", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if o.Size != int64(len(data)) || o.ETag != "abc" {
+		t.Errorf("Size, ETag = %d, %q want %d, %q", o.Size, o.ETag, len(data), "abc")
+	}
+	if len(reqs) != 1 {
+		t.Errorf("requests = %q want only HEAD", reqs)
+	}
+
+	tests := []struct {
+		off int64
+		n   int
+		w   string
+		err error
+		rng string
+	}{
+		{2, 3, "234", nil, "bytes=2-4"},
+		{0, 10, data, nil, "bytes=0-9"},
+		{8, 5, "89", io.EOF, "bytes=8-9"},
+		{10, 1, "", io.EOF, ""},
+	}
+	for _, ts := range tests {
+		reqs = nil
+		p := make([]byte, ts.n)
+		n, err := o.ReadAt(p, ts.off)
+		if g := string(p[:n]); g != ts.w || err != ts.err {
+			t.Errorf("ReadAt(%d, %d) = %q, %v want %q, %v", ts.n, ts.off, g, err, ts.w, ts.err)
+		}
+		var w []string
+		if ts.rng != "" {
+			w = []string{"GET " + ts.rng}
+		}
+		if strings.Join(reqs, ",") != strings.Join(w, ",") {
+			t.Errorf("ReadAt(%d, %d): requests = %q want %q", ts.n, ts.off, reqs, w)
+		}
+	}
+
+	reqs = nil
+	b, err := ioutil.ReadAll(o)
+	if err != nil || string(b) != data {
+		t.Errorf("ReadAll = %q, %v want %q", b, err, data)
+	}
+	if len(reqs) != 1 || reqs[0] != "GET " {
+		t.Errorf("requests = %q want one GET", reqs)
+	}
+	if err := o.Close(); err != nil {
+		t.Error("unexpected err", err)
+	}
+}
+
+// A server that ignores Range sends the whole object with status
+// 200, which ReadAt must not take for the bytes it asked for.
+func TestObjectReadAtIgnoredRange(t *testing.T) {
+	const data = "01234567890123456789"
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Length": {strconv.Itoa(len(data))}},
+				Body:       ioutil.NopCloser(strings.NewReader(data)),
+			}
+			return resp, nil
+		}),
+	}
+	o, err := OpenObject("https://johnsmith.s3.amazonaws.com/digits", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	p := make([]byte, 4)
+	if n, err := o.ReadAt(p, 10); err == nil {
+		t.Errorf("ReadAt = %q, nil want error", p[:n])
+	}
+}
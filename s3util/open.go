@@ -1,9 +1,16 @@
 package s3util
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
 	"io"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 )
 
 // Open requests the S3 object at url. An HTTP status other than 200 is
@@ -11,23 +18,127 @@ import (
 //
 // If c is nil, Open uses DefaultConfig.
 func Open(url string, c *Config) (io.ReadCloser, error) {
+	resp, err := open(context.Background(), url, nil, 200, c)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// OpenVerified is like Open, but the returned reader checks the
+// object's contents against its MD5 digest, as given by its ETag.
+// Read returns an error in place of io.EOF if they differ.
+// The ETag of an object uploaded in parts, or encrypted with
+// SSE-KMS or SSE-C, is not an MD5 digest, so such objects are
+// not checked. Neither is an object stored with Content-Encoding
+// gzip that the transport decompressed, since its ETag is the
+// digest of the compressed bytes.
+func OpenVerified(url string, c *Config) (io.ReadCloser, error) {
+	resp, err := open(context.Background(), url, nil, 200, c)
+	if err != nil {
+		return nil, err
+	}
+	etag := strings.Trim(resp.Header.Get("Etag"), `"`)
+	sum, err := hex.DecodeString(etag)
+	if err != nil || len(sum) != md5.Size || encrypted(resp) || resp.Uncompressed {
+		return resp.Body, nil
+	}
+	return &md5Reader{ReadCloser: resp.Body, h: md5.New(), want: sum}, nil
+}
+
+// md5Reader reads from an object and, at the end,
+// checks its MD5 digest.
+type md5Reader struct {
+	io.ReadCloser
+	h    hash.Hash
+	want []byte
+}
+
+func (r *md5Reader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF {
+		if got := r.h.Sum(nil); !bytes.Equal(got, r.want) {
+			return n, fmt.Errorf("md5 mismatch: got %x want %x", got, r.want)
+		}
+	}
+	return n, err
+}
+
+// OpenRange requests bytes start through end, inclusive, of the S3
+// object at url. If end is -1, it requests everything from start to
+// the end of the object. An HTTP status other than 206 (Partial
+// Content) is considered an error, as is a response for a different
+// range, such as a server that ignores the request's range would give.
+//
+// If c is nil, OpenRange uses DefaultConfig.
+func OpenRange(url string, start, end int64, c *Config) (io.ReadCloser, error) {
+	return openRange(context.Background(), url, start, end, c)
+}
+
+func openRange(ctx context.Context, url string, start, end int64, c *Config) (io.ReadCloser, error) {
+	h := http.Header{}
+	h.Set("Range", byteRange(start, end))
+	resp, err := open(ctx, url, h, http.StatusPartialContent, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkContentRange(resp, start, end); err != nil {
+		discard(resp)
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func byteRange(start, end int64) string {
+	s := "bytes=" + strconv.FormatInt(start, 10) + "-"
+	if end >= 0 {
+		s += strconv.FormatInt(end, 10)
+	}
+	return s
+}
+
+// checkContentRange returns an error unless resp, a response to
+// a request for bytes start through end, or to the end of the object
+// if end is -1, has a Content-Range header for those bytes. The range
+// may end early at the end of the object.
+func checkContentRange(resp *http.Response, start, end int64) error {
+	cr := resp.Header.Get("Content-Range")
+	var first, last int64
+	var size string
+	_, err := fmt.Sscanf(cr, "bytes %d-%d/%s", &first, &last, &size)
+	n, sizeErr := strconv.ParseInt(size, 10, 64)
+	switch {
+	case err != nil || first != start:
+	case end < 0 || last == end:
+		return nil
+	case last < end && sizeErr == nil && last == n-1:
+		return nil
+	}
+	return fmt.Errorf("received Content-Range %q for Range %q", cr, byteRange(start, end))
+}
+
+// open requests the object at url, adding the entries of h to the
+// request header. An HTTP status other than status is an error.
+// Cancelling ctx cancels the request.
+func open(ctx context.Context, url string, h http.Header, status int, c *Config) (*http.Response, error) {
 	if c == nil {
 		c = DefaultConfig
 	}
 	// TODO(kr): maybe parallel range fetching
 	r, _ := http.NewRequest("GET", url, nil)
-	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	c.Sign(r, *c.Keys)
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
+	r = r.WithContext(ctx)
+	for k := range h {
+		for _, v := range h[k] {
+			r.Header.Add(k, v)
+		}
 	}
-	resp, err := client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 200 && resp.StatusCode != http.StatusPartialContent {
+	if resp.StatusCode != status {
 		return nil, newRespError(resp)
 	}
-	return resp.Body, nil
+	return resp, nil
 }
@@ -0,0 +1,176 @@
+package s3util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sqs/s3"
+)
+
+var openRangeTest = []struct {
+	start, end int64
+	w          string
+	cr         string // Content-Range of the response
+}{
+	{0, 1023, "bytes=0-1023", "bytes 0-1023/5000"},
+	{100, 100, "bytes=100-100", "bytes 100-100/5000"},
+	{4096, -1, "bytes=4096-", "bytes 4096-4999/5000"},
+	{4096, 8191, "bytes=4096-8191", "bytes 4096-4999/5000"},
+}
+
+func TestOpenRange(t *testing.T) {
+	for _, ts := range openRangeTest {
+		var got string
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req.Header.Get("Range")
+				resp := &http.Response{
+					StatusCode: http.StatusPartialContent,
+					Header:     http.Header{"Content-Range": {ts.cr}},
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+				return resp, nil
+			}),
+		}
+		r, err := OpenRange("https://s3.amazonaws.com/foo/bar", ts.start, ts.end, &c)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		r.Close()
+		if got != ts.w {
+			t.Errorf("Range = %q want %q", got, ts.w)
+		}
+	}
+}
+
+// A server that ignores Range, or answers for other bytes,
+// must not be mistaken for one that honors it.
+func TestOpenRangeMismatch(t *testing.T) {
+	tests := []struct {
+		status int
+		cr     string
+	}{
+		{200, ""},
+		{206, ""},
+		{206, "bytes 0-1023/5000"},
+		{206, "bytes 100-1023/5000"},
+		{206, "bytes 100-199/5000"},
+		{206, "bytes 100-1023/*"},
+	}
+	for _, ts := range tests {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp := &http.Response{
+					StatusCode: ts.status,
+					Header:     http.Header{"Content-Range": {ts.cr}},
+					Body:       ioutil.NopCloser(strings.NewReader("0123456789")),
+				}
+				return resp, nil
+			}),
+		}
+		if _, err := OpenRange("https://s3.amazonaws.com/foo/bar", 100, 2047, &c); err == nil {
+			t.Errorf("status %d, Content-Range %q: expected error", ts.status, ts.cr)
+		}
+	}
+}
+
+func TestOpenAnonymous(t *testing.T) {
+	c := *DefaultConfig
+	c.Keys = new(s3.Keys)
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if g := req.Header.Get("Authorization"); g != "" {
+				t.Errorf("Authorization = %q want none", g)
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+			return resp, nil
+		}),
+	}
+	r, err := Open("https://mybucket.s3.amazonaws.com/public.txt", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	r.Close()
+}
+
+func TestOpenVerified(t *testing.T) {
+	const body = "hello, world"
+	tests := []struct {
+		etag string
+		ok   bool
+	}{
+		{`"e4d7f1b4ed2e42d15898f4b27b019da4"`, true},   // md5 of body
+		{`"00000000000000000000000000000000"`, false},  // mismatch
+		{`"ceb8853ddc5086cc4ab9e149f8f09c88-2"`, true}, // multipart, not checked
+	}
+	for _, ts := range tests {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp := &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Etag": {ts.etag}},
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+				}
+				return resp, nil
+			}),
+		}
+		r, err := OpenVerified("https://s3.amazonaws.com/foo/bar", &c)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if string(b) != body {
+			t.Errorf("etag %s: read %q want %q", ts.etag, b, body)
+		}
+		if (err == nil) != ts.ok {
+			t.Errorf("etag %s: err = %v, want ok %v", ts.etag, err, ts.ok)
+		}
+	}
+}
+
+// Go's transport asks for gzip and decompresses the body, so
+// the MD5 digest of what Read returns is not the ETag of an
+// object stored compressed.
+func TestOpenVerifiedGzip(t *testing.T) {
+	const body = "hello, world"
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(body))
+	zw.Close()
+	sum := md5.Sum(gz.Bytes())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Etag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.Write(gz.Bytes())
+	}))
+	defer srv.Close()
+	c := *DefaultConfig
+	c.Keys = new(s3.Keys)
+	c.Client = &http.Client{}
+	r, err := OpenVerified(srv.URL+"/foo/bar.txt", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if string(b) != body {
+		t.Errorf("read %q want %q", b, body)
+	}
+}
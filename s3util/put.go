@@ -0,0 +1,88 @@
+package s3util
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrPreconditionFailed is returned by PutIfNoneMatch and PutIfMatch
+// if S3 refuses the write because its condition doesn't hold.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// PutIfNoneMatch writes body to the S3 object at url in a single
+// request, only if no such object exists yet. If one does, it
+// returns ErrPreconditionFailed. Any other HTTP status than 200
+// is considered an error.
+//
+// If c is nil, PutIfNoneMatch uses DefaultConfig.
+func PutIfNoneMatch(url string, body io.ReadSeeker, c *Config) error {
+	h := http.Header{}
+	h.Set("If-None-Match", "*")
+	return put(url, h, body, c)
+}
+
+// PutIfMatch writes body to the S3 object at url in a single
+// request, only if the object exists and its ETag is etag, as
+// when it was last read. This allows for optimistic concurrency
+// control: if the object has changed or been deleted since, it
+// returns ErrPreconditionFailed. Any other HTTP status than 200
+// is considered an error. The etag may be quoted, as returned
+// by Head.
+//
+// If c is nil, PutIfMatch uses DefaultConfig.
+func PutIfMatch(url, etag string, body io.ReadSeeker, c *Config) error {
+	h := http.Header{}
+	h.Set("If-Match", `"`+strings.Trim(etag, `"`)+`"`)
+	return put(url, h, body, c)
+}
+
+func put(url string, h http.Header, body io.ReadSeeker, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r, err := http.NewRequest("PUT", url, ioutil.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	r.ContentLength = size
+	if size == 0 {
+		r.Body = http.NoBody
+	}
+	r.GetBody = func() (io.ReadCloser, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(body), nil
+	}
+	for k := range h {
+		for _, v := range h[k] {
+			r.Header.Add(k, v)
+		}
+	}
+	if r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", defaultContentType)
+	}
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		discard(resp)
+		return ErrPreconditionFailed
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	discard(resp)
+	return nil
+}
@@ -0,0 +1,98 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sqs/s3"
+)
+
+func TestPutConditional(t *testing.T) {
+	const data = "hello, world"
+	var status int
+	var header http.Header
+	c := *DefaultConfig
+	c.Service = &s3.Service{Domain: "amazonaws.com", Version: 4}
+	c.Keys = &s3.Keys{AccessKey: "AKID", SecretKey: "secret"}
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != "PUT" {
+				t.Errorf("method = %q want PUT", req.Method)
+			}
+			b, _ := ioutil.ReadAll(req.Body)
+			if string(b) != data || req.ContentLength != int64(len(data)) {
+				t.Errorf("body = %q (length %d) want %q", b, req.ContentLength, data)
+			}
+			header = req.Header
+			resp := &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+			return resp, nil
+		}),
+	}
+	const url = "https://johnsmith.s3.amazonaws.com/lock"
+	tests := []struct {
+		put    func() error
+		header string
+		w      string
+	}{
+		{func() error { return PutIfNoneMatch(url, strings.NewReader(data), &c) }, "If-None-Match", "*"},
+		{func() error { return PutIfMatch(url, "abc", strings.NewReader(data), &c) }, "If-Match", `"abc"`},
+		{func() error { return PutIfMatch(url, `"abc"`, strings.NewReader(data), &c) }, "If-Match", `"abc"`},
+	}
+	for _, ts := range tests {
+		status = 200
+		if err := ts.put(); err != nil {
+			t.Errorf("%s: unexpected err %v", ts.header, err)
+		}
+		if g := header.Get(ts.header); g != ts.w {
+			t.Errorf("%s = %q want %q", ts.header, g, ts.w)
+		}
+		if !strings.Contains(header.Get("Authorization"), strings.ToLower(ts.header)) {
+			t.Errorf("%s is not signed: %q", ts.header, header.Get("Authorization"))
+		}
+
+		status = http.StatusPreconditionFailed
+		if err := ts.put(); err != ErrPreconditionFailed {
+			t.Errorf("%s: err = %v want ErrPreconditionFailed", ts.header, err)
+		}
+
+		status = http.StatusForbidden
+		if err := ts.put(); err == nil || err == ErrPreconditionFailed {
+			t.Errorf("%s: err = %v want status 403 error", ts.header, err)
+		}
+	}
+}
+
+// The ETag from Head is quoted, and must not be quoted again.
+func TestPutIfMatchHeadETag(t *testing.T) {
+	var ifMatch string
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Etag": {`"abc"`}},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+			if req.Method == "PUT" {
+				ifMatch = req.Header.Get("If-Match")
+			}
+			return resp, nil
+		}),
+	}
+	const url = "https://johnsmith.s3.amazonaws.com/lock"
+	h, err := Head(url, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := PutIfMatch(url, h.Get("Etag"), strings.NewReader("hello"), &c); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if ifMatch != `"abc"` {
+		t.Errorf("If-Match = %q want %q", ifMatch, `"abc"`)
+	}
+}
@@ -153,13 +153,7 @@ func (f *File) sendRequest(count int) (io.ReadCloser, error) {
 	}
 	u := buf.String()
 	r, _ := http.NewRequest("GET", u, nil)
-	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	c.Sign(r, *c.Keys)
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
-	}
-	resp, err := client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, err
 	}
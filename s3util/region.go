@@ -0,0 +1,55 @@
+package s3util
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// BucketRegion returns the region of the named bucket, such as
+// "eu-west-1", so that it can be assigned to the Region field of
+// an s3.Service. It asks the us-east-1 endpoint, which reports the
+// region of any bucket in header X-Amz-Bucket-Region, and falls
+// back to requesting the bucket's location constraint.
+//
+// If c is nil, BucketRegion uses DefaultConfig.
+func BucketRegion(bucket string, c *Config) (string, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	rc := *c
+	svc := *c.Service
+	svc.Region = ""
+	rc.Service = &svc
+	url := svc.URL(bucket, "")
+
+	r, _ := http.NewRequest("HEAD", url, nil)
+	resp, err := rc.do(r)
+	if err != nil {
+		return "", err
+	}
+	discard(resp)
+	if region := resp.Header.Get("X-Amz-Bucket-Region"); region != "" {
+		return region, nil
+	}
+
+	r, _ = http.NewRequest("GET", url+"?location", nil)
+	resp, err = rc.do(r)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != 200 {
+		return "", newRespError(resp)
+	}
+	defer discard(resp)
+	var loc string
+	if err := xml.NewDecoder(resp.Body).Decode(&loc); err != nil {
+		return "", err
+	}
+	switch loc {
+	case "":
+		return "us-east-1", nil
+	case "EU":
+		return "eu-west-1", nil
+	}
+	return loc, nil
+}
@@ -0,0 +1,55 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var bucketRegionTest = []struct {
+	header   string
+	location string
+	w        string
+}{
+	{"eu-central-1", "", "eu-central-1"},
+	{"", `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">ap-northeast-1</LocationConstraint>`, "ap-northeast-1"},
+	{"", `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`, "us-east-1"},
+	{"", `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">EU</LocationConstraint>`, "eu-west-1"},
+}
+
+func TestBucketRegion(t *testing.T) {
+	for _, ts := range bucketRegionTest {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Host != "johnsmith.s3.amazonaws.com" {
+					t.Errorf("host = %q want us-east-1 endpoint", req.URL.Host)
+				}
+				resp := &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+				switch {
+				case req.Method == "HEAD":
+					if ts.header != "" {
+						resp.Header.Set("X-Amz-Bucket-Region", ts.header)
+					}
+				case req.Method == "GET" && req.URL.RawQuery == "location":
+					resp.Body = ioutil.NopCloser(strings.NewReader(ts.location))
+				default:
+					t.Fatal("unexpected request", req)
+				}
+				return resp, nil
+			}),
+		}
+		g, err := BucketRegion("johnsmith", &c)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if g != ts.w {
+			t.Errorf("region = %q want %q", g, ts.w)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package s3util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"net/http"
+)
+
+// ErrRestoreInProgress is returned by Restore if a restore of the
+// object has already been started and has not yet finished.
+var ErrRestoreInProgress = errors.New("restore already in progress")
+
+type restoreRequest struct {
+	XMLName              xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ RestoreRequest"`
+	Days                 int
+	GlacierJobParameters *glacierJobParameters
+}
+
+type glacierJobParameters struct {
+	Tier string
+}
+
+// Restore starts restoring a temporary copy of the archived S3
+// object at url, in storage class GLACIER or DEEP_ARCHIVE, that
+// can be read for the given number of days. Tier is the speed of
+// retrieval: "Expedited", "Standard", or "Bulk". If tier is empty,
+// S3 uses Standard.
+//
+// Restore returns nil once the restore has started, or if the
+// object has already been restored, in which case the copy's
+// expiry is updated to the given days. If a restore is already
+// in progress, Restore returns ErrRestoreInProgress. Any other
+// HTTP status is considered an error.
+//
+// If c is nil, Restore uses DefaultConfig.
+func Restore(url string, days int, tier string, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	req := restoreRequest{Days: days}
+	if tier != "" {
+		req.GlacierJobParameters = &glacierJobParameters{tier}
+	}
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(body)
+	r, err := http.NewRequest("POST", url+"?restore", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		discard(resp)
+		return nil
+	case http.StatusConflict:
+		discard(resp)
+		return ErrRestoreInProgress
+	}
+	return newRespError(resp)
+}
@@ -0,0 +1,73 @@
+package s3util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		days   int
+		tier   string
+		status int
+		body   string
+		err    error
+	}{
+		{
+			2, "Bulk", 202,
+			`<RestoreRequest xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Days>2</Days><GlacierJobParameters><Tier>Bulk</Tier></GlacierJobParameters></RestoreRequest>`,
+			nil,
+		},
+		{
+			7, "", 200,
+			`<RestoreRequest xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Days>7</Days></RestoreRequest>`,
+			nil,
+		},
+		{
+			1, "Expedited", 409,
+			`<RestoreRequest xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Days>1</Days><GlacierJobParameters><Tier>Expedited</Tier></GlacierJobParameters></RestoreRequest>`,
+			ErrRestoreInProgress,
+		},
+	}
+	for _, ts := range tests {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if g, w := req.Method+" "+req.URL.RawQuery, "POST restore"; g != w {
+					t.Errorf("request = %q want %q", g, w)
+				}
+				b, _ := ioutil.ReadAll(req.Body)
+				if string(b) != ts.body {
+					t.Errorf("body = %q want %q", b, ts.body)
+				}
+				resp := &http.Response{
+					StatusCode: ts.status,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+				return resp, nil
+			}),
+		}
+		if err := Restore("https://johnsmith.s3.amazonaws.com/archive.tar", ts.days, ts.tier, &c); err != ts.err {
+			t.Errorf("status %d: err = %v want %v", ts.status, err, ts.err)
+		}
+	}
+}
+
+func TestRestoreError(t *testing.T) {
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: 403,
+				Body:       ioutil.NopCloser(strings.NewReader("<Error><Code>InvalidObjectState</Code></Error>")),
+			}
+			return resp, nil
+		}),
+	}
+	err := Restore("https://johnsmith.s3.amazonaws.com/archive.tar", 1, "", &c)
+	if err == nil || !strings.Contains(err.Error(), "InvalidObjectState") {
+		t.Errorf("err = %v want InvalidObjectState", err)
+	}
+}
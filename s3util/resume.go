@@ -0,0 +1,97 @@
+package s3util
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Part describes a part of a multipart upload that S3 has received.
+type Part struct {
+	PartNumber int
+	ETag       string // without quotes
+	Size       int64
+}
+
+// ListParts returns the parts S3 has received so far for the
+// multipart upload with the given ID to the object at url.
+// An HTTP status other than 200 is considered an error.
+//
+// If c is nil, ListParts uses DefaultConfig.
+func ListParts(url, uploadID string, c *Config) ([]Part, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	var parts []Part
+	marker := 0
+	for {
+		var result struct {
+			IsTruncated          bool
+			NextPartNumberMarker int
+			Part                 []Part
+		}
+		if err := listParts(url, uploadID, marker, c, &result); err != nil {
+			return nil, err
+		}
+		for _, p := range result.Part {
+			p.ETag = strings.Trim(p.ETag, `"`)
+			parts = append(parts, p)
+		}
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func listParts(u, uploadID string, marker int, c *Config, result interface{}) error {
+	v := url.Values{}
+	v.Set("uploadId", uploadID)
+	if marker > 0 {
+		v.Set("part-number-marker", strconv.Itoa(marker))
+	}
+	r, _ := http.NewRequest("GET", u+"?"+v.Encode(), nil)
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	defer discard(resp)
+	return xml.NewDecoder(resp.Body).Decode(result)
+}
+
+// Resume continues the multipart upload with the given ID to the
+// object at url, as started by Create and interrupted before it was
+// closed. The ID is available from the writer returned by Create,
+// through its method UploadID() string.
+//
+// The whole object must be written again, from the beginning.
+// Parts that S3 already has, with the same size and contents,
+// are not sent again, so c.PartSize should be the same as before.
+//
+// If c is nil, Resume uses DefaultConfig.
+func Resume(url, uploadID string, c *Config) (io.WriteCloser, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	if err := checkPartSize(c.PartSize); err != nil {
+		return nil, err
+	}
+	parts, err := ListParts(url, uploadID, c)
+	if err != nil {
+		return nil, err
+	}
+	u := initUploader(url, c)
+	u.UploadId = uploadID
+	u.done = make(map[int]Part)
+	for _, p := range parts {
+		u.done[p.PartNumber] = p
+	}
+	u.start()
+	return u, nil
+}
@@ -0,0 +1,92 @@
+package s3util
+
+import (
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResume(t *testing.T) {
+	size1 := int64(minPartSize)
+	size2 := size1 + size1/1000
+	sum1 := md5.Sum(make([]byte, size1))
+	sum2 := md5.Sum(make([]byte, size2))
+	pages := map[string]string{
+		"": fmt.Sprintf(`<ListPartsResult><IsTruncated>true</IsTruncated><NextPartNumberMarker>1</NextPartNumberMarker>
+			<Part><PartNumber>1</PartNumber><ETag>"%x"</ETag><Size>%d</Size></Part></ListPartsResult>`, sum1, size1),
+		"1": fmt.Sprintf(`<ListPartsResult><IsTruncated>false</IsTruncated>
+			<Part><PartNumber>2</PartNumber><ETag>"%x"</ETag><Size>%d</Size></Part></ListPartsResult>`, sum2, size2),
+	}
+
+	var (
+		mu       sync.Mutex
+		put      []string
+		complete []byte
+	)
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var s string
+			q := req.URL.Query()
+			if g := q.Get("uploadId"); g != "foo" {
+				t.Errorf("uploadId = %q want foo", g)
+			}
+			switch req.Method {
+			case "GET":
+				s = pages[q.Get("part-number-marker")]
+			case "PUT":
+				mu.Lock()
+				put = append(put, q.Get("partNumber"))
+				mu.Unlock()
+				io.Copy(ioutil.Discard, req.Body)
+			case "POST":
+				complete, _ = ioutil.ReadAll(req.Body)
+			default:
+				t.Fatal("unexpected request", req)
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(s)),
+				Header:     http.Header{"Etag": {`"bar"`}},
+			}
+			return resp, nil
+		}),
+	}
+	w, err := Resume("https://s3.amazonaws.com/foo/bar", "foo", &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if _, err := io.Copy(w, io.LimitReader(devZero, size1+size2+100)); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if len(put) != 1 || put[0] != "3" {
+		t.Errorf("uploaded parts %q want [3]", put)
+	}
+	var doc struct {
+		Part []struct {
+			PartNumber int
+			ETag       string
+		}
+	}
+	if err := xml.Unmarshal(complete, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{fmt.Sprintf("%x", sum1), fmt.Sprintf("%x", sum2), "bar"}
+	if len(doc.Part) != len(want) {
+		t.Fatalf("completed %d parts want %d", len(doc.Part), len(want))
+	}
+	for i, p := range doc.Part {
+		if p.PartNumber != i+1 || p.ETag != want[i] {
+			t.Errorf("part %d = %d %q want %d %q", i, p.PartNumber, p.ETag, i+1, want[i])
+		}
+	}
+}
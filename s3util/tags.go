@@ -0,0 +1,103 @@
+package s3util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"unicode/utf8"
+)
+
+// Limits on object tags, defined by amazon.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/object-tagging.html.
+const (
+	maxTags        = 10
+	maxTagKeyLen   = 128
+	maxTagValueLen = 256
+)
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Tag     []tag    `xml:"TagSet>Tag"`
+}
+
+type tag struct {
+	Key   string
+	Value string
+}
+
+// GetTags returns the tags of the S3 object at url.
+// An HTTP status other than 200 is considered an error.
+//
+// If c is nil, GetTags uses DefaultConfig.
+func GetTags(url string, c *Config) (map[string]string, error) {
+	if c == nil {
+		c = DefaultConfig
+	}
+	r, _ := http.NewRequest("GET", url+"?tagging", nil)
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRespError(resp)
+	}
+	defer discard(resp)
+	var doc tagging
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	for _, t := range doc.Tag {
+		tags[t.Key] = t.Value
+	}
+	return tags, nil
+}
+
+// PutTags replaces the tags of the S3 object at url with tags.
+// An object can have at most 10 tags, each with a key of at most
+// 128 characters and a value of at most 256. An HTTP status other
+// than 200 is considered an error.
+//
+// If c is nil, PutTags uses DefaultConfig.
+func PutTags(url string, tags map[string]string, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	if len(tags) > maxTags {
+		return fmt.Errorf("%d tags exceeds limit of %d", len(tags), maxTags)
+	}
+	var doc tagging
+	for k, v := range tags {
+		if k == "" || utf8.RuneCountInString(k) > maxTagKeyLen {
+			return fmt.Errorf("tag key %q must be 1 to %d characters long", k, maxTagKeyLen)
+		}
+		if utf8.RuneCountInString(v) > maxTagValueLen {
+			return fmt.Errorf("value of tag %q exceeds %d characters", k, maxTagValueLen)
+		}
+		doc.Tag = append(doc.Tag, tag{k, v})
+	}
+	sort.Slice(doc.Tag, func(i, j int) bool { return doc.Tag[i].Key < doc.Tag[j].Key })
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(body)
+	r, err := http.NewRequest("PUT", url+"?tagging", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return newRespError(resp)
+	}
+	discard(resp)
+	return nil
+}
@@ -0,0 +1,84 @@
+package s3util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	var stored []byte
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.RawQuery != "tagging" {
+				t.Errorf("query = %q want tagging", req.URL.RawQuery)
+			}
+			var body []byte
+			switch req.Method {
+			case "PUT":
+				b, _ := ioutil.ReadAll(req.Body)
+				sum := md5.Sum(b)
+				if g, w := req.Header.Get("Content-MD5"), base64.StdEncoding.EncodeToString(sum[:]); g != w {
+					t.Errorf("Content-MD5 = %q want %q", g, w)
+				}
+				stored = b
+			case "GET":
+				body = stored
+			default:
+				t.Errorf("method = %q", req.Method)
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			}
+			return resp, nil
+		}),
+	}
+	const url = "https://johnsmith.s3.amazonaws.com/photos/puppy.jpg"
+	tags := map[string]string{"project": "blue", "owner": "john smith"}
+	if err := PutTags(url, tags, &c); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	w := "<Tagging><TagSet><Tag><Key>owner</Key><Value>john smith</Value></Tag><Tag><Key>project</Key><Value>blue</Value></Tag></TagSet></Tagging>"
+	if string(stored) != w {
+		t.Errorf("body = %q want %q", stored, w)
+	}
+	got, err := GetTags(url, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if !reflect.DeepEqual(got, tags) {
+		t.Errorf("tags = %v want %v", got, tags)
+	}
+}
+
+func TestPutTagsInvalid(t *testing.T) {
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("unexpected request")
+			return nil, nil
+		}),
+	}
+	many := map[string]string{}
+	for _, k := range strings.Split("abcdefghijk", "") {
+		many[k] = k
+	}
+	bad := []map[string]string{
+		many,
+		{"": "x"},
+		{strings.Repeat("k", 129): "x"},
+		{"k": strings.Repeat("v", 257)},
+	}
+	for _, tags := range bad {
+		if err := PutTags("https://johnsmith.s3.amazonaws.com/puppy.jpg", tags, &c); err == nil {
+			t.Errorf("PutTags(%d tags) = nil, want error", len(tags))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package s3util
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Upload creates an S3 object at url holding the first size bytes
+// of r, sending its parts in parallel with multipart upload requests.
+// Each part reads from r through its own io.SectionReader, so unlike
+// Create, Upload doesn't buffer the object in memory. An *os.File
+// is a good r.
+//
+// Parts are c.PartSize bytes long, except the last. If that would
+// take more than the 10,000 parts S3 allows, Upload uses the
+// smallest part size that fits the object in 10,000 parts.
+// Up to c.Concurrency parts are sent at once.
+//
+// If h is not nil, each of its entries is added to the HTTP request header.
+// If h has no Content-Type, the object's type is application/octet-stream.
+// If c is nil, Upload uses DefaultConfig.
+func Upload(url string, r io.ReaderAt, size int64, h http.Header, c *Config) error {
+	if c == nil {
+		c = DefaultConfig
+	}
+	if size > maxObjSize {
+		return errors.New("object too large")
+	}
+	if err := checkPartSize(c.PartSize); err != nil {
+		return err
+	}
+	u, err := newUploader(url, h, c)
+	if err != nil {
+		return err
+	}
+	n := partSize(size, c.PartSize)
+	for off := int64(0); off < size && u.getErr() == nil; off += n {
+		u.part++
+		p := &part{
+			r:          io.NewSectionReader(r, off, min(n, size-off)),
+			len:        min(n, size-off),
+			PartNumber: u.part,
+		}
+		u.xml.Part = append(u.xml.Part, p)
+		u.wg.Add(1)
+		u.ch <- p
+	}
+	return u.Close()
+}
+
+// partSize returns the part size to use for an object of the
+// given size, given the size n asked for: n, or minPartSize if n
+// is zero, raised as needed to fit the object into maxNPart parts.
+func partSize(size, n int64) int64 {
+	if n == 0 {
+		n = minPartSize
+	}
+	if m := (size + maxNPart - 1) / maxNPart; n < m {
+		n = m
+	}
+	return n
+}
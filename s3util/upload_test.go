@@ -0,0 +1,196 @@
+package s3util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpload(t *testing.T) {
+	const size = 2*minPartSize + 1000
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i / minPartSize) // distinct content per part
+	}
+	f, err := ioutil.TempFile("", "s3util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	parts := map[int][]byte{}
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var s string
+			switch q := req.URL.Query(); {
+			case req.Method == "PUT":
+				b, _ := ioutil.ReadAll(req.Body)
+				sum := md5.Sum(b)
+				if g, w := req.Header.Get("Content-MD5"), base64.StdEncoding.EncodeToString(sum[:]); g != w {
+					t.Errorf("Content-MD5 = %q want %q", g, w)
+				}
+				n, _ := strconv.Atoi(q.Get("partNumber"))
+				mu.Lock()
+				parts[n] = b
+				mu.Unlock()
+			case req.Method == "POST" && q["uploads"] != nil:
+				s = `<UploadId>foo</UploadId>`
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(s)),
+				Header:     http.Header{"Etag": {`"foo"`}},
+			}
+			return resp, nil
+		}),
+	}
+	if err := Upload("https://s3.amazonaws.com/foo/bar", f, size, nil, &c); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("uploaded %d parts want 3", len(parts))
+	}
+	var got []byte
+	for i := 1; i <= len(parts); i++ {
+		got = append(got, parts[i]...)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("uploaded parts don't match the file")
+	}
+}
+
+func TestUploadPartSize(t *testing.T) {
+	const partSize = minPartSize + 1024*1024
+	const size = 2*partSize + 10
+	var mu sync.Mutex
+	lens := map[int]int{}
+	c := *DefaultConfig
+	c.PartSize = partSize
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var s string
+			switch q := req.URL.Query(); {
+			case req.Method == "PUT":
+				b, _ := ioutil.ReadAll(req.Body)
+				n, _ := strconv.Atoi(q.Get("partNumber"))
+				mu.Lock()
+				lens[n] = len(b)
+				mu.Unlock()
+			case req.Method == "POST" && q["uploads"] != nil:
+				s = `<UploadId>foo</UploadId>`
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(s)),
+				Header:     http.Header{"Etag": {`"foo"`}},
+			}
+			return resp, nil
+		}),
+	}
+	r := bytes.NewReader(make([]byte, size))
+	if err := Upload("https://s3.amazonaws.com/foo/bar", r, size, nil, &c); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	w := map[int]int{1: partSize, 2: partSize, 3: 10}
+	if !reflect.DeepEqual(lens, w) {
+		t.Errorf("part sizes = %v want %v", lens, w)
+	}
+}
+
+func TestPartSizeInvalid(t *testing.T) {
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Fatal("unexpected request", req.Method, req.URL)
+			return nil, nil
+		}),
+	}
+	const url = "https://s3.amazonaws.com/foo/bar"
+	for _, n := range []int64{minPartSize - 1, maxPartSize + 1} {
+		c.PartSize = n
+		if _, err := Create(url, nil, &c); err == nil {
+			t.Errorf("Create with part size %d: expected error", n)
+		}
+		if _, err := Resume(url, "foo", &c); err == nil {
+			t.Errorf("Resume with part size %d: expected error", n)
+		}
+		if err := Upload(url, bytes.NewReader(nil), 0, nil, &c); err == nil {
+			t.Errorf("Upload with part size %d: expected error", n)
+		}
+	}
+}
+
+func TestPartSize(t *testing.T) {
+	tests := []struct {
+		size, n, w int64
+	}{
+		{0, 0, minPartSize},
+		{minPartSize * maxNPart, 0, minPartSize},
+		{minPartSize*maxNPart + 1, 0, minPartSize + 1},
+		{maxObjSize, 0, (maxObjSize + maxNPart - 1) / maxNPart},
+		{100 * minPartSize, 2 * minPartSize, 2 * minPartSize},
+		{2*minPartSize*maxNPart + 1, 2 * minPartSize, 2*minPartSize + 1},
+	}
+	for _, ts := range tests {
+		if g := partSize(ts.size, ts.n); g != ts.w {
+			t.Errorf("partSize(%d, %d) = %d want %d", ts.size, ts.n, g, ts.w)
+		}
+	}
+}
+
+func TestUploadConcurrency(t *testing.T) {
+	const size = 4 * minPartSize
+	for _, n := range []int{1, 3} {
+		var mu sync.Mutex
+		var cur, max int
+		c := *DefaultConfig
+		c.Concurrency = n
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				var s string
+				if req.Method == "PUT" {
+					mu.Lock()
+					if cur++; cur > max {
+						max = cur
+					}
+					mu.Unlock()
+					ioutil.ReadAll(req.Body)
+					time.Sleep(20 * time.Millisecond)
+					mu.Lock()
+					cur--
+					mu.Unlock()
+				} else if req.Method == "POST" && req.URL.Query()["uploads"] != nil {
+					s = `<UploadId>foo</UploadId>`
+				}
+				resp := &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(s)),
+					Header:     http.Header{"Etag": {`"foo"`}},
+				}
+				return resp, nil
+			}),
+		}
+		r := bytes.NewReader(make([]byte, size))
+		if err := Upload("https://s3.amazonaws.com/foo/bar", r, size, nil, &c); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if max != n {
+			t.Errorf("Concurrency %d: sent %d parts at once", n, max)
+		}
+	}
+}
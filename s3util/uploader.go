@@ -2,12 +2,17 @@ package s3util
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -24,13 +29,15 @@ const (
 )
 
 const (
-	concurrency = 5
-	nTry        = 2
+	defaultConcurrency = 5
+	nTry               = 2
+	defaultContentType = "application/octet-stream"
 )
 
 type part struct {
 	r   io.ReadSeeker
 	len int64
+	md5 string // base64-encoded
 
 	// read by xml encoder
 	PartNumber int
@@ -40,18 +47,24 @@ type part struct {
 type uploader struct {
 	s3       s3.Service
 	keys     s3.Keys
+	provider s3.Provider
 	url      string
+	h        http.Header // for an empty object's PUT
 	client   *http.Client
 	UploadId string // written by xml decoder
 
+	concurrency int // number of workers
+
 	bufsz  int64
 	buf    []byte
 	off    int
 	ch     chan *part
 	part   int
 	closed bool
+	mu     sync.Mutex // guards err, which workers set
 	err    error
 	wg     sync.WaitGroup
+	done   map[int]Part // parts uploaded before Resume
 
 	xml struct {
 		XMLName string `xml:"CompleteMultipartUpload"`
@@ -62,12 +75,25 @@ type uploader struct {
 // Create creates an S3 object at url and sends multipart upload requests as
 // data is written.
 //
+// Each part is sent with a Content-MD5 header, so S3 rejects
+// any part that is corrupted in transit.
+//
+// The returned writer has a method UploadID() string, for use with Resume.
+//
+// The first part is c.PartSize bytes long, and later ones grow
+// slowly from there, so that objects up to 5TiB fit in the 10,000
+// parts S3 allows. Up to c.Concurrency parts are sent at once.
+//
 // If h is not nil, each of its entries is added to the HTTP request header.
+// If h has no Content-Type, the object's type is application/octet-stream.
 // If c is nil, Create uses DefaultConfig.
 func Create(url string, h http.Header, c *Config) (io.WriteCloser, error) {
 	if c == nil {
 		c = DefaultConfig
 	}
+	if err := checkPartSize(c.PartSize); err != nil {
+		return nil, err
+	}
 	return newUploader(url, h, c)
 }
 
@@ -76,51 +102,98 @@ func Create(url string, h http.Header, c *Config) (io.WriteCloser, error) {
 // This initial request returns an UploadId that we use to identify
 // subsequent PUT requests.
 func newUploader(url string, h http.Header, c *Config) (u *uploader, err error) {
-	u = new(uploader)
-	u.s3 = *c.Service
-	u.url = url
-	u.keys = *c.Keys
-	u.client = c.Client
-	if u.client == nil {
-		u.client = http.DefaultClient
-	}
-	u.bufsz = minPartSize
+	u = initUploader(url, c)
 	r, err := http.NewRequest("POST", url+"?uploads", nil)
 	if err != nil {
 		return nil, err
 	}
 	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	u.h = http.Header{}
 	for k := range h {
 		for _, v := range h[k] {
+			u.h.Add(k, v)
+		}
+	}
+	if u.h.Get("Content-Type") == "" {
+		u.h.Set("Content-Type", defaultContentType)
+	}
+	for k := range u.h {
+		for _, v := range u.h[k] {
 			r.Header.Add(k, v)
 		}
 	}
-	u.s3.Sign(r, u.keys)
+	if err := u.sign(r); err != nil {
+		return nil, err
+	}
 	resp, err := u.client.Do(r)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return nil, newRespError(resp)
 	}
+	defer discard(resp)
 	err = xml.NewDecoder(resp.Body).Decode(u)
 	if err != nil {
 		return nil, err
 	}
+	u.start()
+	return u, nil
+}
+
+func initUploader(url string, c *Config) *uploader {
+	u := new(uploader)
+	u.s3 = *c.Service
+	u.url = url
+	if c.Keys != nil {
+		u.keys = *c.Keys
+	}
+	u.provider = c.Provider
+	u.client = c.Client
+	if u.client == nil {
+		u.client = http.DefaultClient
+	}
+	u.bufsz = minPartSize
+	if c.PartSize != 0 {
+		u.bufsz = c.PartSize
+	}
+	u.concurrency = defaultConcurrency
+	if c.Concurrency > 0 {
+		u.concurrency = c.Concurrency
+	}
+	return u
+}
+
+// checkPartSize returns an error if n, a part size given
+// in a Config, is outside the limits S3 allows.
+func checkPartSize(n int64) error {
+	if n != 0 && n < minPartSize {
+		return fmt.Errorf("part size %d is less than the minimum of %d", n, minPartSize)
+	}
+	if n > maxPartSize {
+		return fmt.Errorf("part size %d exceeds the maximum of %d", n, maxPartSize)
+	}
+	return nil
+}
+
+func (u *uploader) start() {
 	u.ch = make(chan *part)
-	for i := 0; i < concurrency; i++ {
+	for i := 0; i < u.concurrency; i++ {
 		go u.worker()
 	}
-	return u, nil
+}
+
+// UploadID returns the ID of the multipart upload, for use with Resume.
+func (u *uploader) UploadID() string {
+	return u.UploadId
 }
 
 func (u *uploader) Write(p []byte) (n int, err error) {
 	if u.closed {
 		return 0, syscall.EINVAL
 	}
-	if u.err != nil {
-		return 0, u.err
+	if err := u.getErr(); err != nil {
+		return 0, err
 	}
 	for n < len(p) {
 		if cap(u.buf) == 0 {
@@ -141,10 +214,25 @@ func (u *uploader) Write(p []byte) (n int, err error) {
 }
 
 func (u *uploader) flush() {
-	u.wg.Add(1)
 	u.part++
-	p := &part{bytes.NewReader(u.buf[:u.off]), int64(u.off), u.part, ""}
+	p := &part{
+		r:          bytes.NewReader(u.buf[:u.off]),
+		len:        int64(u.off),
+		PartNumber: u.part,
+	}
 	u.xml.Part = append(u.xml.Part, p)
+	if d, ok := u.done[u.part]; ok && d.Size == p.len {
+		sum := md5.Sum(u.buf[:u.off])
+		p.md5 = base64.StdEncoding.EncodeToString(sum[:])
+		if d.ETag == hex.EncodeToString(sum[:]) {
+			// Already uploaded before the upload was resumed.
+			p.r = nil
+			p.ETag = d.ETag
+			u.buf, u.off = nil, 0
+			return
+		}
+	}
+	u.wg.Add(1)
 	u.ch <- p
 	u.buf, u.off = nil, 0
 }
@@ -159,6 +247,13 @@ func (u *uploader) worker() {
 func (u *uploader) retryUploadPart(p *part) {
 	defer u.wg.Done()
 	defer func() { p.r = nil }() // free the large buffer
+	if p.md5 == "" {
+		// Computed here, rather than in flush,
+		// so the writer need not wait for it.
+		h := md5.New()
+		io.Copy(h, p.r)
+		p.md5 = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
 	var err error
 	for i := 0; i < nTry; i++ {
 		p.r.Seek(0, 0)
@@ -167,7 +262,15 @@ func (u *uploader) retryUploadPart(p *part) {
 			return
 		}
 	}
+	u.mu.Lock()
 	u.err = err
+	u.mu.Unlock()
+}
+
+func (u *uploader) getErr() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.err
 }
 
 // Uploads part p, reading its contents from p.r.
@@ -181,16 +284,24 @@ func (u *uploader) putPart(p *part) error {
 		return err
 	}
 	req.ContentLength = p.len
+	if p.len >= minPartSize {
+		// Let S3 refuse the part, say for bad credentials,
+		// before we send megabytes of data.
+		req.Header.Set("Expect", "100-continue")
+	}
+	req.Header.Set("Content-MD5", p.md5)
 	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	u.s3.Sign(req, u.keys)
+	if err := u.sign(req); err != nil {
+		return err
+	}
 	resp, err := u.client.Do(req)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return newRespError(resp)
 	}
+	discard(resp)
 	s := resp.Header.Get("etag") // includes quote chars for some reason
 	if len(s) < 2 {
 		return fmt.Errorf("received invalid etag %q", s)
@@ -209,9 +320,9 @@ func (u *uploader) Close() error {
 	u.wg.Wait()
 	close(u.ch)
 	u.closed = true
-	if u.err != nil {
+	if err := u.getErr(); err != nil {
 		u.abort()
-		return u.err
+		return err
 	}
 
 	if u.part == 0 {
@@ -224,8 +335,15 @@ func (u *uploader) Close() error {
 		if err != nil {
 			return err
 		}
+		for k := range u.h {
+			for _, v := range u.h[k] {
+				req.Header.Add(k, v)
+			}
+		}
 		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-		u.s3.Sign(req, u.keys)
+		if err := u.sign(req); err != nil {
+			return err
+		}
 		resp, err := u.client.Do(req)
 		if err != nil {
 			return err
@@ -233,7 +351,7 @@ func (u *uploader) Close() error {
 		if resp.StatusCode != 200 {
 			return newRespError(resp)
 		}
-		resp.Body.Close()
+		discard(resp)
 		return nil
 	}
 
@@ -249,7 +367,9 @@ func (u *uploader) Close() error {
 		return err
 	}
 	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	u.s3.Sign(req, u.keys)
+	if err := u.sign(req); err != nil {
+		return err
+	}
 	resp, err := u.client.Do(req)
 	if err != nil {
 		return err
@@ -257,10 +377,52 @@ func (u *uploader) Close() error {
 	if resp.StatusCode != 200 {
 		return newRespError(resp)
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	// S3 can report a failed completion with status 200,
+	// so look for an Error document in the body.
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		XMLName xml.Name
+		ETag    string
+	}
+	if xml.Unmarshal(body, &result) != nil {
+		return nil
+	}
+	if result.XMLName.Local == "Error" {
+		e := new(respError)
+		e.r = resp
+		e.b.Write(body)
+		return e
+	}
+	if etag := strings.Trim(result.ETag, `"`); etag != "" && !encrypted(resp) {
+		if want := u.etag(); etag != want {
+			return fmt.Errorf("received etag %q want %q", etag, want)
+		}
+	}
 	return nil
 }
 
+// etag returns the ETag S3 should give the uploaded object.
+func (u *uploader) etag() string {
+	var sums [][]byte
+	for _, p := range u.xml.Part {
+		sum, _ := base64.StdEncoding.DecodeString(p.md5)
+		sums = append(sums, sum)
+	}
+	return MultipartETag(sums)
+}
+
+// encrypted reports whether resp describes an object encrypted
+// with SSE-KMS or SSE-C, whose ETag is not based on MD5 digests.
+func encrypted(resp *http.Response) bool {
+	return resp.Header.Get("X-Amz-Server-Side-Encryption") == "aws:kms" ||
+		resp.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != ""
+}
+
 func (u *uploader) abort() {
 	// TODO(kr): devise a reasonable way to report an error here in addition
 	// to the error that caused the abort.
@@ -272,15 +434,31 @@ func (u *uploader) abort() {
 		return
 	}
 	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	u.s3.Sign(req, u.keys)
+	if u.sign(req) != nil {
+		return
+	}
 	resp, err := u.client.Do(req)
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return
+	discard(resp)
+}
+
+// sign signs r with the keys from u.provider, if any,
+// or else u.keys. It returns s3.ErrExpired if they have expired.
+func (u *uploader) sign(r *http.Request) error {
+	k := &u.keys
+	if u.provider != nil {
+		var err error
+		if k, err = u.provider.Keys(); err != nil {
+			return err
+		}
 	}
+	if k.Expired() {
+		return s3.ErrExpired
+	}
+	u.s3.Sign(r, *k)
+	return nil
 }
 
 func min(a, b int64) int64 {
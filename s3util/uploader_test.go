@@ -1,12 +1,19 @@
 package s3util
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func runUpload(t *testing.T, makeCloser func(io.Reader) io.ReadCloser) *uploader {
@@ -148,16 +155,293 @@ func TestEmptyEtag(t *testing.T) {
 	if err != nil {
 		t.Fatal("unexpected err", err)
 	}
-	const size = minPartSize + minPartSize/3
-	n, err := io.Copy(u, io.LimitReader(devZero, size))
+	// Fill the first part, then wait for its upload to fail,
+	// so that the next write sees the error.
+	if _, err := u.Write(make([]byte, minPartSize)); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	u.wg.Wait()
+	n, err := u.Write(make([]byte, minPartSize/3))
 	if err == nil || err.Error() != `received invalid etag ""` {
 		t.Fatalf("expected err: %q", err)
 	}
-	if n != minPartSize {
-		t.Fatalf("wrote %d bytes want %d", n, minPartSize)
+	if n != 0 {
+		t.Fatalf("wrote %d bytes want 0", n)
 	}
 	err = u.Close()
 	if err == nil || err.Error() != `received invalid etag ""` {
 		t.Fatalf("expected err: %q", err)
 	}
 }
+
+func TestUploaderContentMD5(t *testing.T) {
+	var nPut int32
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var s string
+			switch q := req.URL.Query(); {
+			case req.Method == "PUT":
+				atomic.AddInt32(&nPut, 1)
+				b, _ := ioutil.ReadAll(req.Body)
+				sum := md5.Sum(b)
+				w := base64.StdEncoding.EncodeToString(sum[:])
+				if g := req.Header.Get("Content-MD5"); g != w {
+					t.Errorf("Content-MD5 = %q want %q", g, w)
+				}
+			case req.Method == "POST" && q["uploads"] != nil:
+				s = `<UploadId>foo</UploadId>`
+			}
+			resp := &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(s)),
+				Header: http.Header{
+					"Etag": {`"foo"`},
+				},
+			}
+			return resp, nil
+		}),
+	}
+	u, err := newUploader("https://s3.amazonaws.com/foo/bar", nil, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	const size = minPartSize + minPartSize/3
+	if _, err := io.Copy(u, io.LimitReader(strings.NewReader(strings.Repeat("abc", size)), size)); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := u.Close(); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if nPut != 2 {
+		t.Errorf("put %d parts want 2", nPut)
+	}
+}
+
+func TestUploaderContentType(t *testing.T) {
+	tests := []struct {
+		h    http.Header
+		want string
+	}{
+		{nil, "application/octet-stream"},
+		{http.Header{"Content-Type": {"text/plain"}}, "text/plain"},
+	}
+	for _, ts := range tests {
+		var got string
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req.Header.Get("Content-Type")
+				resp := &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(`<UploadId>foo</UploadId>`)),
+				}
+				return resp, nil
+			}),
+		}
+		if _, err := newUploader("https://s3.amazonaws.com/foo/bar", ts.h, &c); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if got != ts.want {
+			t.Errorf("Content-Type = %q want %q", got, ts.want)
+		}
+	}
+}
+
+// An empty object is written with a plain PUT,
+// which must carry the same headers as the upload.
+func TestUploaderEmptyHeader(t *testing.T) {
+	tests := []struct {
+		h    http.Header
+		want string
+	}{
+		{nil, "application/octet-stream"},
+		{http.Header{"Content-Type": {"application/json"}, "X-Amz-Acl": {"public-read"}}, "application/json"},
+	}
+	for _, ts := range tests {
+		var put http.Header
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Method == "PUT" {
+					put = req.Header
+				}
+				resp := &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(`<UploadId>foo</UploadId>`)),
+				}
+				return resp, nil
+			}),
+		}
+		u, err := newUploader("https://s3.amazonaws.com/foo/bar", ts.h, &c)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if err := u.Close(); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if put == nil {
+			t.Fatal("no PUT request")
+		}
+		if g := put.Get("Content-Type"); g != ts.want {
+			t.Errorf("Content-Type = %q want %q", g, ts.want)
+		}
+		if g, w := put.Get("X-Amz-Acl"), ts.h.Get("X-Amz-Acl"); g != w {
+			t.Errorf("X-Amz-Acl = %q want %q", g, w)
+		}
+	}
+}
+
+func TestUploaderExpectContinue(t *testing.T) {
+	var code int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g := r.Header.Get("Expect"); g != "100-continue" {
+			t.Errorf("Expect = %q want 100-continue", g)
+		}
+		w.WriteHeader(code) // without reading the body
+	}))
+	defer srv.Close()
+	for _, code = range []int{http.StatusForbidden, http.StatusTemporaryRedirect} {
+		u := &uploader{
+			s3:       *DefaultConfig.Service,
+			url:      srv.URL + "/foo/bar",
+			client:   &http.Client{Transport: &http.Transport{ExpectContinueTimeout: time.Minute}},
+			UploadId: "foo",
+		}
+		body := bytes.NewReader(make([]byte, minPartSize))
+		p := &part{r: body, len: minPartSize, PartNumber: 1}
+		if err := u.putPart(p); err == nil {
+			t.Errorf("status %d: expected error", code)
+		}
+		if n := body.Len(); n != minPartSize {
+			t.Errorf("status %d: %d bytes of body sent want 0", code, minPartSize-n)
+		}
+	}
+}
+
+func TestUploaderCompleteETag(t *testing.T) {
+	const size = minPartSize + 100
+	sum1 := md5.Sum(make([]byte, minPartSize))
+	sum2 := md5.Sum(make([]byte, 100))
+	good := MultipartETag([][]byte{sum1[:], sum2[:]})
+	tests := []struct {
+		body string
+		ok   bool
+	}{
+		{`<CompleteMultipartUploadResult><ETag>"` + good + `"</ETag></CompleteMultipartUploadResult>`, true},
+		{`<CompleteMultipartUploadResult><ETag>"3858f62230ac3c915f300c664312c11f-2"</ETag></CompleteMultipartUploadResult>`, false},
+		{`<Error><Code>InternalError</Code></Error>`, false},
+	}
+	for _, ts := range tests {
+		c := *DefaultConfig
+		c.Client = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				var s string
+				switch q := req.URL.Query(); {
+				case req.Method == "POST" && q["uploads"] != nil:
+					s = `<UploadId>foo</UploadId>`
+				case req.Method == "POST" && q["uploadId"] != nil:
+					s = ts.body
+				}
+				resp := &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(s)),
+					Header:     http.Header{"Etag": {`"foo"`}},
+				}
+				return resp, nil
+			}),
+		}
+		u, err := newUploader("https://s3.amazonaws.com/foo/bar", nil, &c)
+		if err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if _, err := io.Copy(u, io.LimitReader(devZero, size)); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+		if err := u.Close(); (err == nil) != ts.ok {
+			t.Errorf("body %s: Close = %v, want ok %v", ts.body, err, ts.ok)
+		}
+	}
+}
+
+// trackedBody counts response bodies that are still open,
+// and those closed before being read to the end.
+type trackedBody struct {
+	io.Reader
+	open, undrained *int32
+	eof             bool
+}
+
+func newTrackedBody(s string, open, undrained *int32) *trackedBody {
+	atomic.AddInt32(open, 1)
+	return &trackedBody{Reader: strings.NewReader(s), open: open, undrained: undrained}
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	atomic.AddInt32(b.open, -1)
+	if !b.eof {
+		atomic.AddInt32(b.undrained, 1)
+	}
+	return nil
+}
+
+func TestUploaderRetryDrainsBodies(t *testing.T) {
+	var open, undrained, nPut int32
+	var mu sync.Mutex
+	tried := map[string]bool{}
+	c := *DefaultConfig
+	c.Client = &http.Client{
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			status, s := 200, ""
+			switch q := req.URL.Query(); {
+			case req.Method == "PUT":
+				// Fail the first try of each part.
+				atomic.AddInt32(&nPut, 1)
+				mu.Lock()
+				if n := q.Get("partNumber"); !tried[n] {
+					tried[n] = true
+					status, s = 500, "<Error><Code>InternalError</Code></Error>"
+				}
+				mu.Unlock()
+			case req.Method == "POST" && q["uploads"] != nil:
+				s = `<InitiateMultipartUploadResult><UploadId>foo</UploadId></InitiateMultipartUploadResult>`
+			case req.Method == "POST" && q["uploadId"] != nil:
+				s = `<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`
+			}
+			resp := &http.Response{
+				StatusCode: status,
+				Body:       newTrackedBody(s, &open, &undrained),
+				Header:     http.Header{"Etag": {`"foo"`}},
+			}
+			return resp, nil
+		}),
+	}
+	u, err := newUploader("https://s3.amazonaws.com/foo/bar", nil, &c)
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	u.bufsz = 1024
+	if _, err := io.Copy(u, io.LimitReader(devZero, 3*1024)); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := u.Close(); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if nPut != 6 {
+		t.Errorf("put %d times want 6", nPut)
+	}
+	if open != 0 {
+		t.Errorf("%d response bodies left open", open)
+	}
+	if undrained != 0 {
+		t.Errorf("%d response bodies closed before EOF", undrained)
+	}
+}
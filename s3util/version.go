@@ -0,0 +1,30 @@
+package s3util
+
+import (
+	"io"
+	"net/url"
+	"strings"
+)
+
+// OpenVersion is like Open, but requests the given version of
+// the object at url, in a bucket with versioning enabled.
+// Requesting a version that is a delete marker is an error.
+func OpenVersion(url, versionID string, c *Config) (io.ReadCloser, error) {
+	return Open(versionURL(url, versionID), c)
+}
+
+// DeleteVersion is like Delete, but permanently deletes the given
+// version of the object at url, in a bucket with versioning enabled,
+// rather than adding a delete marker.
+func DeleteVersion(url, versionID string, c *Config) (io.ReadCloser, error) {
+	return Delete(versionURL(url, versionID), c)
+}
+
+// versionURL returns u with parameter versionId set to id.
+func versionURL(u, id string) string {
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "versionId=" + url.QueryEscape(id)
+}
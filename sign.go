@@ -5,6 +5,7 @@ package s3
 // http://docs.amazonwebservices.com/AmazonS3/2006-03-01/dev/RESTAuthentication.html.
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -12,17 +13,22 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 )
 
 var signParams = map[string]bool{
+	"accelerate":                   true,
 	"acl":                          true,
+	"cors":                         true,
 	"delete":                       true,
+	"encryption":                   true,
 	"lifecycle":                    true,
 	"location":                     true,
 	"logging":                      true,
 	"notification":                 true,
 	"partNumber":                   true,
 	"policy":                       true,
+	"replication":                  true,
 	"requestPayment":               true,
 	"response-cache-control":       true,
 	"response-content-disposition": true,
@@ -31,6 +37,7 @@ var signParams = map[string]bool{
 	"response-content-type":        true,
 	"response-expires":             true,
 	"restore":                      true,
+	"tagging":                      true,
 	"torrent":                      true,
 	"uploadId":                     true,
 	"uploads":                      true,
@@ -50,6 +57,18 @@ type Keys struct {
 	// before signing a request.
 	SecurityToken string
 	// See http://docs.aws.amazon.com/AmazonS3/latest/dev/MakingRequests.html#TypesofSecurityCredentials
+
+	// Expires is the time at which temporary credentials expire.
+	// The zero value means the keys never expire.
+	Expires time.Time
+}
+
+var timeNow = time.Now // for testing
+
+// Expired reports whether k has an expiration time
+// and it has passed.
+func (k *Keys) Expired() bool {
+	return !k.Expires.IsZero() && timeNow().After(k.Expires)
 }
 
 // IdentityBucket returns subdomain.
@@ -60,12 +79,28 @@ func IdentityBucket(subdomain string) string {
 	return subdomain
 }
 
-// AmazonBucket returns everything up to the last '.' in subdomain.
+// AmazonBucket returns everything up to the last ".s3" segment
+// in subdomain, or, if there is none, up to the last '.'.
+// If subdomain begins with an "s3" segment, there is no bucket.
 // It is designed to be used with the Amazon service.
-//   "johnsmith.s3"           becomes "johnsmith"
-//   "johnsmith.s3-eu-west-1" becomes "johnsmith"
-//   "www.example.com.s3"     becomes "www.example.com"
+//   "johnsmith.s3"              becomes "johnsmith"
+//   "johnsmith.s3-eu-west-1"    becomes "johnsmith"
+//   "johnsmith.s3.eu-central-1" becomes "johnsmith"
+//   "www.example.com.s3"        becomes "www.example.com"
 func AmazonBucket(subdomain string) string {
+	for j := len(subdomain); j > 0; {
+		i := strings.LastIndex(subdomain[:j], ".s3")
+		if i == -1 {
+			break
+		}
+		if n := i + 3; n == len(subdomain) || subdomain[n] == '.' || subdomain[n] == '-' {
+			return subdomain[:i]
+		}
+		j = i
+	}
+	if subdomain == "s3" || strings.HasPrefix(subdomain, "s3.") || strings.HasPrefix(subdomain, "s3-") {
+		return "" // path-style request
+	}
 	if i := strings.LastIndex(subdomain, "."); i != -1 {
 		return subdomain[:i]
 	}
@@ -83,6 +118,8 @@ func Sign(r *http.Request, k Keys) {
 }
 
 // Service represents an S3-compatible service.
+// Its methods may be called from multiple goroutines
+// at once, as long as its fields are not modified.
 type Service struct {
 	// Domain is the service's root domain. It is used to extract
 	// the subdomain from an http.Request before passing the
@@ -92,20 +129,145 @@ type Service struct {
 	// Bucket derives the bucket name from a subdomain.
 	// If nil, AmazonBucket is used.
 	Bucket func(subdomain string) string
+
+	// Region is the region used in the credential scope of
+	// version 4 signatures. If empty, "us-east-1" is used.
+	Region string
+
+	// Endpoint, if set, is the base URL of an S3-compatible
+	// service, such as "https://minio.example.com:9000".
+	// It takes the place of Domain and Region, both for URL
+	// and for extracting the bucket name when signing. If
+	// Bucket is nil, IdentityBucket is used for hosts under
+	// Endpoint. URL uses the scheme of Endpoint, so a local
+	// server without TLS can be given as "http://localhost:9000".
+	// The scheme is not part of the signature.
+	Endpoint string
+
+	// DualStack makes URL use Amazon's dual-stack endpoints,
+	// which accept requests over both IPv4 and IPv6.
+	DualStack bool
+
+	// Accelerate makes URL use Amazon's Transfer Acceleration
+	// endpoint where possible.
+	Accelerate bool
+
+	// FIPS makes URL use Amazon's FIPS 140-2 validated endpoints.
+	// Only some regions have them; see CheckFIPS.
+	FIPS bool
+
+	// PathStyle makes URL put the bucket name in the path
+	// rather than in the host name.
+	PathStyle bool
+
+	// Version is the signature version used by Sign.
+	// It may be 2 or 4. If zero, version 2 is used.
+	Version int
+
+	// Header holds headers that Sign adds to every request,
+	// such as X-Amz-Meta- headers. A header already present
+	// in the request is left as it is.
+	Header http.Header
+
+	// RequesterPays makes Sign add header X-Amz-Request-Payer,
+	// as SetRequesterPays does, to every request, for use with
+	// Requester Pays buckets.
+	RequesterPays bool
+
+	// UnsignedPayload makes version 4 signatures of HTTPS requests
+	// leave the body out, rather than reading it to compute its
+	// hash. The body is still protected by TLS. Requests over
+	// plain HTTP are signed with the hash of their body regardless.
+	UnsignedPayload bool
+
+	// SignedHeaders, if set, limits the headers covered by version 4
+	// signatures to Host, X-Amz- headers, and the headers named here,
+	// such as "Content-Type". Other headers, say ones a proxy adds or
+	// rewrites, are sent unsigned. If nil, every header is signed
+	// except Authorization, Expect, and User-Agent.
+	SignedHeaders []string
+
+	// OnSign, if set, is called by Sign with the canonical request
+	// and the string to sign, just before they are signed. It is
+	// meant for debugging a SignatureDoesNotMatch error, whose
+	// body holds the strings the service computed. Version 2
+	// signatures have no canonical request, so it is empty.
+	OnSign func(canonicalRequest, stringToSign string)
+
+	// Transport is the transport that Client and ProviderClient
+	// use to send requests once they are signed, for instance to
+	// add tracing or metrics. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// UseAmzDate makes Client and ProviderClient stamp requests
+	// with header X-Amz-Date, rather than Date, which some
+	// S3-compatible services handle better when signing.
+	UseAmzDate bool
 }
 
 // Sign signs an HTTP request with the given S3 keys for use on service s.
+//
+// If k has neither an access key nor a secret key, Sign leaves r
+// unsigned, so it is sent as an anonymous request, as for reading
+// from a public bucket.
 func (s *Service) Sign(r *http.Request, k Keys) {
+	for name, vs := range s.Header {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := r.Header[name]; !ok {
+			r.Header[name] = append([]string(nil), vs...)
+		}
+	}
+	if k.AccessKey == "" && k.SecretKey == "" {
+		return
+	}
 	if k.SecurityToken != "" {
 		r.Header.Set("X-Amz-Security-Token", k.SecurityToken)
 	}
+	if s.RequesterPays {
+		SetRequesterPays(r)
+	}
+	if s.Version == 4 {
+		s.sign4(r, k)
+		return
+	}
 	h := hmac.New(sha1.New, []byte(k.SecretKey))
-	s.writeSigData(h, r)
+	if s.OnSign != nil {
+		var sts bytes.Buffer
+		s.writeSigData(&sts, r)
+		s.OnSign("", sts.String())
+		h.Write(sts.Bytes())
+	} else {
+		s.writeSigData(h, r)
+	}
 	sig := make([]byte, base64.StdEncoding.EncodedLen(h.Size()))
 	base64.StdEncoding.Encode(sig, h.Sum(nil))
 	r.Header.Set("Authorization", "AWS "+k.AccessKey+":"+string(sig))
 }
 
+// SignAt is like Sign, but signs r as if at time t, rather than
+// at the time in its Date or X-Amz-Date header. It sets whichever
+// of those headers the signature uses. Signatures made at the same
+// time are the same, which is useful for tests.
+func (s *Service) SignAt(r *http.Request, k Keys, t time.Time) {
+	t = t.UTC()
+	if _, ok := r.Header["X-Amz-Date"]; ok || s.Version == 4 {
+		r.Header.Set("X-Amz-Date", t.Format(s.amzDateFormat()))
+	} else {
+		r.Header.Set("Date", t.Format(http.TimeFormat))
+	}
+	s.Sign(r, k)
+}
+
+// amzDateFormat returns the format of header X-Amz-Date
+// for s's signature version. Version 2 uses the same
+// format as header Date.
+func (s *Service) amzDateFormat() string {
+	if s.Version == 4 {
+		return timeFormat4
+	}
+	return http.TimeFormat
+}
+
 func (s *Service) writeSigData(w io.Writer, r *http.Request) {
 	w.Write([]byte(r.Method))
 	w.Write([]byte{'\n'})
@@ -136,15 +298,23 @@ func (s *Service) writeVhostBucket(w io.Writer, host string) {
 		host = host[:i]
 	}
 
-	if host == s.Domain {
-		// no vhost - do nothing
-	} else if strings.HasSuffix(host, "."+s.Domain) {
-		// vhost - bucket may be in prefix
-		b := s.Bucket
+	domain := s.domain()
+	b := s.Bucket
+	if u := s.endpoint(); u != nil {
+		domain = u.Hostname()
 		if b == nil {
-			b = AmazonBucket
+			b = IdentityBucket
 		}
-		bucket := b(host[:len(host)-len(s.Domain)-1])
+	}
+	if b == nil {
+		b = AmazonBucket
+	}
+
+	if host == domain {
+		// no vhost - do nothing
+	} else if strings.HasSuffix(host, "."+domain) {
+		// vhost - bucket may be in prefix
+		bucket := b(host[:len(host)-len(domain)-1])
 
 		if bucket != "" {
 			w.Write([]byte{'/'})
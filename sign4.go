@@ -0,0 +1,311 @@
+package s3
+
+// See
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	algorithm4      = "AWS4-HMAC-SHA256"
+	timeFormat4     = "20060102T150405Z"
+	dateFormat4     = "20060102"
+	defaultRegion   = "us-east-1"
+	emptySHA256     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// Headers that are never included in a version 4 signature,
+// because proxies and transports are free to change them.
+var unsignedHeaders = map[string]bool{
+	"authorization": true,
+	"expect":        true,
+	"user-agent":    true,
+}
+
+// sign4 signs r using AWS Signature Version 4.
+func (s *Service) sign4(r *http.Request, k Keys) {
+	t := requestTime4(r)
+	if r.Header.Get("X-Amz-Content-Sha256") == "" {
+		if s.UnsignedPayload && r.URL.Scheme == "https" {
+			r.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+		} else {
+			r.Header.Set("X-Amz-Content-Sha256", payloadHash(r))
+		}
+	}
+	scope := s.scope(t)
+
+	var creq bytes.Buffer
+	signed := s.writeCanonicalRequest(&creq, r, r.Header.Get("X-Amz-Content-Sha256"))
+
+	var sts bytes.Buffer
+	writeStringToSign(&sts, t, scope, creq.Bytes())
+	if s.OnSign != nil {
+		s.OnSign(creq.String(), sts.String())
+	}
+	key := signingKey(k.SecretKey, t.Format(dateFormat4), s.region())
+	sig := hex.EncodeToString(hmacSHA256(key, sts.Bytes()))
+
+	r.Header.Set("Authorization", algorithm4+
+		" Credential="+k.AccessKey+"/"+scope+
+		", SignedHeaders="+signed+
+		", Signature="+sig)
+}
+
+func (s *Service) region() string {
+	if s.Region == "" {
+		return defaultRegion
+	}
+	return s.Region
+}
+
+// scope returns the credential scope for a signature made at time t.
+func (s *Service) scope(t time.Time) string {
+	return t.Format(dateFormat4) + "/" + s.region() + "/s3/aws4_request"
+}
+
+// requestTime4 returns the signing time of r, taken from header
+// X-Amz-Date, else from header Date, else the current time.
+// It sets X-Amz-Date if it is not already present.
+func requestTime4(r *http.Request) time.Time {
+	if t, err := time.Parse(timeFormat4, r.Header.Get("X-Amz-Date")); err == nil {
+		return t
+	}
+	t, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		t = timeNow()
+	}
+	t = t.UTC()
+	r.Header.Set("X-Amz-Date", t.Format(timeFormat4))
+	return t
+}
+
+// payloadHash returns the hex-encoded SHA-256 digest of r's body.
+// If the body cannot be read without consuming it, payloadHash
+// returns UNSIGNED-PAYLOAD; callers that want the payload signed
+// should set header X-Amz-Content-Sha256 themselves.
+func payloadHash(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return emptySHA256
+	}
+	if r.GetBody == nil {
+		return unsignedPayload
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return unsignedPayload
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return unsignedPayload
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCanonicalRequest writes the canonical form of r, whose body
+// has the given hash, to w and returns the list of signed headers.
+func (s *Service) writeCanonicalRequest(w io.Writer, r *http.Request, payload string) string {
+	w.Write([]byte(r.Method))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(canonicalURI(r.URL)))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(canonicalQuery(r.URL)))
+	w.Write([]byte{'\n'})
+	signed := s.writeCanonicalHeaders(w, r)
+	w.Write([]byte{'\n'})
+	w.Write([]byte(signed))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(payload))
+	return signed
+}
+
+// canonicalURI returns the path of u as it is sent on the wire,
+// with each segment re-encoded by uriEncode. Working from the
+// escaped path keeps an escaped "/" within a segment distinct
+// from a path separator.
+func canonicalURI(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	a := strings.Split(p, "/")
+	for i, seg := range a {
+		if v, err := url.PathUnescape(seg); err == nil {
+			seg = v
+		}
+		a[i] = uriEncode(seg, true)
+	}
+	return strings.Join(a, "/")
+}
+
+func canonicalQuery(u *url.URL) string {
+	var a []string
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			a = append(a, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(a)
+	return strings.Join(a, "&")
+}
+
+// writeCanonicalHeaders writes the canonical headers of r to w,
+// one per line, and returns the list of signed headers.
+func (s *Service) writeCanonicalHeaders(w io.Writer, r *http.Request) string {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	m := map[string]string{"host": host}
+	for k, vs := range r.Header {
+		k = strings.ToLower(k)
+		if !s.signsHeader(k) {
+			continue
+		}
+		a := make([]string, len(vs))
+		for i, v := range vs {
+			a[i] = strings.Join(strings.Fields(v), " ")
+		}
+		m[k] = strings.Join(a, ",")
+	}
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.Write([]byte(k))
+		w.Write([]byte{':'})
+		w.Write([]byte(m[k]))
+		w.Write([]byte{'\n'})
+	}
+	return strings.Join(keys, ";")
+}
+
+// signsHeader reports whether the header with lower case name k
+// is covered by version 4 signatures.
+func (s *Service) signsHeader(k string) bool {
+	if unsignedHeaders[k] {
+		return false
+	}
+	if s.SignedHeaders == nil || strings.HasPrefix(k, "x-amz-") {
+		return true
+	}
+	for _, h := range s.SignedHeaders {
+		if strings.EqualFold(h, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeStringToSign(w io.Writer, t time.Time, scope string, creq []byte) {
+	h := sha256.Sum256(creq)
+	w.Write([]byte(algorithm4))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(t.Format(timeFormat4)))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(scope))
+	w.Write([]byte{'\n'})
+	w.Write([]byte(hex.EncodeToString(h[:])))
+}
+
+// signingKeys caches derived signing keys. A key is valid for
+// a single day, so only the latest one for each secret and region
+// is kept. Temporary keys are replaced often, so the cache holds
+// at most maxSigningKeys entries, to not keep old secrets forever.
+var signingKeys struct {
+	sync.Mutex
+	m map[signingKeyID]signingKeyEntry
+}
+
+const maxSigningKeys = 8
+
+type signingKeyID struct {
+	secret, region string
+}
+
+type signingKeyEntry struct {
+	date string
+	key  []byte
+}
+
+// signingKey returns the key for signing requests made on date
+// in region with the given secret key.
+func signingKey(secret, date, region string) []byte {
+	id := signingKeyID{secret, region}
+	signingKeys.Lock()
+	defer signingKeys.Unlock()
+	if e, ok := signingKeys.m[id]; ok && e.date == date {
+		return e.key
+	}
+	k := deriveSigningKey(secret, date, region)
+	if signingKeys.m == nil {
+		signingKeys.m = make(map[signingKeyID]signingKeyEntry)
+	}
+	if _, ok := signingKeys.m[id]; !ok && len(signingKeys.m) >= maxSigningKeys {
+		// Drop keys for other days, or else an arbitrary one.
+		for old, e := range signingKeys.m {
+			if e.date != date {
+				delete(signingKeys.m, old)
+			}
+		}
+		for old := range signingKeys.m {
+			if len(signingKeys.m) < maxSigningKeys {
+				break
+			}
+			delete(signingKeys.m, old)
+		}
+	}
+	signingKeys.m[id] = signingKeyEntry{date, k}
+	return k
+}
+
+func deriveSigningKey(secret, date, region string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	k = hmacSHA256(k, []byte(region))
+	k = hmacSHA256(k, []byte("s3"))
+	return hmacSHA256(k, []byte("aws4_request"))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// uriEncode percent-encodes every byte of s except the unreserved
+// characters A-Z, a-z, 0-9, '-', '.', '_', and '~'. Character '/'
+// is left alone unless encodeSlash is true.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) || c == '/' && !encodeSlash {
+			b = append(b, c)
+		} else {
+			b = append(b, '%', hexDigits[c>>4], hexDigits[c&15])
+		}
+	}
+	return string(b)
+}
+
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' ||
+		'a' <= c && c <= 'z' ||
+		'0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
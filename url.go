@@ -0,0 +1,177 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URL returns the URL of the object named key in bucket.
+// The key is escaped as needed, but "/" is kept as a path
+// separator.
+//
+// If s.Endpoint is set, the URL is under that endpoint.
+// Otherwise, if s.Bucket is nil, the host name follows Amazon's
+// scheme, bucket.s3.region.domain (or bucket.s3.domain when
+// Region is empty or "us-east-1"); if not, the whole subdomain
+// is the bucket, as in bucket.domain. In the China regions,
+// domain amazonaws.com becomes amazonaws.com.cn. If s.DualStack is set,
+// Amazon host names use the dual-stack (IPv4 and IPv6) endpoint,
+// bucket.s3.dualstack.region.domain. If s.Accelerate is set,
+// they use the Transfer Acceleration endpoint, which has no
+// region, as in bucket.s3-accelerate.domain. If s.FIPS is set,
+// they use the FIPS endpoint, bucket.s3-fips.region.domain,
+// in place of either of those.
+//
+// If s.PathStyle is set, or the bucket name contains dots or
+// upper case letters, which cannot be used in a host name
+// under TLS, the bucket goes in the path instead, as in
+// s3.domain/bucket/key. Transfer Acceleration is not available
+// for such URLs, so they use the regular endpoint.
+//
+// If bucket is empty, URL returns the URL of the service itself,
+// such as https://s3.domain/, and ignores key.
+func (s *Service) URL(bucket, key string) string {
+	scheme := "https"
+	if u := s.endpoint(); u != nil {
+		scheme = u.Scheme
+	}
+	if bucket == "" {
+		return scheme + "://" + s.host("") + "/"
+	}
+	if s.PathStyle || !vhostCompatible(bucket) {
+		return scheme + "://" + s.host("") + "/" + bucket + "/" + uriEncode(key, false)
+	}
+	return scheme + "://" + s.host(bucket) + "/" + uriEncode(key, false)
+}
+
+// host returns the host name for bucket, or for the
+// service itself if bucket is empty.
+func (s *Service) host(bucket string) string {
+	var h string
+	switch u, d := s.endpoint(), s.domain(); {
+	case u != nil:
+		h = u.Host
+	case s.Bucket != nil:
+		h = d
+	case s.FIPS && s.DualStack:
+		h = "s3-fips.dualstack." + s.region() + "." + d
+	case s.FIPS:
+		h = "s3-fips." + s.region() + "." + d
+	case s.Accelerate && bucket != "" && s.DualStack:
+		h = "s3-accelerate.dualstack." + d
+	case s.Accelerate && bucket != "":
+		h = "s3-accelerate." + d
+	case s.DualStack:
+		h = "s3.dualstack." + s.region() + "." + d
+	case s.Region == "" || s.Region == defaultRegion:
+		h = "s3." + d
+	default:
+		h = "s3." + s.Region + "." + d
+	}
+	if bucket != "" {
+		h = bucket + "." + h
+	}
+	return h
+}
+
+// Regions with FIPS endpoints.
+// See https://aws.amazon.com/compliance/fips/.
+var fipsRegions = map[string]bool{
+	"us-east-1":     true,
+	"us-east-2":     true,
+	"us-west-1":     true,
+	"us-west-2":     true,
+	"ca-central-1":  true,
+	"ca-west-1":     true,
+	"us-gov-east-1": true,
+	"us-gov-west-1": true,
+}
+
+// CheckFIPS returns an error if s.FIPS is set but s.Region
+// has no FIPS endpoint, in which case URL returns URLs that
+// do not work.
+func (s *Service) CheckFIPS() error {
+	if s.FIPS && s.endpoint() == nil && s.Bucket == nil && !fipsRegions[s.region()] {
+		return errors.New("s3: region " + s.region() + " has no FIPS endpoint")
+	}
+	return nil
+}
+
+// Partition returns the name of the AWS partition that holds
+// s.Region: "aws-cn" for the China regions, "aws-us-gov" for
+// AWS GovCloud (US), and "aws" for all others.
+func (s *Service) Partition() string {
+	switch r := s.region(); {
+	case strings.HasPrefix(r, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(r, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+// domain returns s.Domain, adjusted for the partition. Amazon's
+// China regions are under amazonaws.com.cn, not amazonaws.com.
+func (s *Service) domain() string {
+	if s.Domain == "amazonaws.com" && s.Partition() == "aws-cn" {
+		return "amazonaws.com.cn"
+	}
+	return s.Domain
+}
+
+// endpoint returns s.Endpoint as a URL,
+// or nil if it is not set or invalid.
+func (s *Service) endpoint() *url.URL {
+	if s.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return u
+}
+
+// vhostCompatible reports whether bucket
+// can be used as a subdomain.
+func vhostCompatible(bucket string) bool {
+	return bucket != "" &&
+		!strings.ContainsAny(bucket, "._") &&
+		strings.ToLower(bucket) == bucket
+}
+
+// ValidBucketName returns an error describing the first rule
+// broken by name, if any, of those Amazon sets for the names of
+// new buckets: 3 to 63 characters, only lower case letters,
+// digits, dots, and hyphens, beginning and ending with a letter
+// or digit, no two adjacent dots, and not shaped like an IP
+// address. Older buckets in us-east-1 may have names that break
+// these rules; URL accepts them.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/BucketRestrictions.html.
+func ValidBucketName(name string) error {
+	if n := len(name); n < 3 || n > 63 {
+		return fmt.Errorf("s3: bucket name %q must be 3 to 63 characters long", name)
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '.' || c == '-') {
+			return fmt.Errorf("s3: bucket name %q contains invalid character %q", name, c)
+		}
+	}
+	if isPunct(name[0]) || isPunct(name[len(name)-1]) {
+		return fmt.Errorf("s3: bucket name %q must begin and end with a letter or digit", name)
+	}
+	if strings.Contains(name, "..") {
+		return fmt.Errorf("s3: bucket name %q contains adjacent dots", name)
+	}
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("s3: bucket name %q is formatted as an IP address", name)
+	}
+	return nil
+}
+
+func isPunct(c byte) bool {
+	return c == '.' || c == '-'
+}